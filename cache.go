@@ -38,6 +38,14 @@ func (c *DriverCache) SetSchema(schema *Schema) {
 	c.schemas.Store(schema.TableName(), schema)
 }
 
+// forEachSchema executes given callback on every cached schema.
+func (c *DriverCache) forEachSchema(callback func(schema *Schema)) {
+	c.schemas.Range(func(key interface{}, value interface{}) bool {
+		callback(value.(*Schema))
+		return true
+	})
+}
+
 // GetSchemaless returns the schemaless associated to type from cache.
 // If it does not exists, it returns nil.
 func (c *DriverCache) GetSchemaless(value reflect.Type) *Schemaless {
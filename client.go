@@ -3,24 +3,42 @@ package makroud
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"io"
 	"math/rand"
+	"strconv"
 	"time"
 
-	"github.com/lib/pq"
 	"github.com/pkg/errors"
+
+	"github.com/ulule/makroud/hooks"
 )
 
-// ClientDriver defines the driver name used in makroud.
+// ClientDriver defines the default driver name used in makroud.
 const ClientDriver = "postgres"
 
+// DefaultPreloadBatchSize defines the default maximum number of keys used by a single preload query.
+const DefaultPreloadBatchSize = 10000
+
 // Client is a wrapper that can interact with the database, it's an implementation of Driver.
 type Client struct {
-	node  Node
-	cache *DriverCache
-	log   Logger
-	obs   Observer
-	rnd   io.Reader
+	node    Node
+	cache   *DriverCache
+	log     Logger
+	obs     Observer
+	rnd     io.Reader
+	slow    time.Duration
+	explain bool
+	nplus   int
+	timeout time.Duration
+	txtime  time.Duration
+	retry   RetryPolicy
+	batch   int
+	dialect Dialect
+	state   *connectionState
+	life    *lifecycle
+	done    func()
 }
 
 // New returns a new Client instance.
@@ -45,10 +63,26 @@ func NewWithOptions(options *ClientOptions) (*Client, error) {
 	}
 
 	entropy := getEntropyForClient(options)
+	dialect := getDialectForClient(options)
+	node.SetDialect(dialect)
 
 	client := &Client{
-		node: node,
-		rnd:  entropy,
+		node:    node,
+		rnd:     entropy,
+		slow:    options.SlowQueryThreshold,
+		explain: options.SlowQueryExplain,
+		nplus:   options.NPlusOneThreshold,
+		timeout: options.QueryTimeout,
+		txtime:  options.TransactionTimeout,
+		retry:   options.RetryPolicy,
+		batch:   options.PreloadBatchSize,
+		dialect: dialect,
+		state:   &connectionState{},
+		life:    &lifecycle{},
+	}
+
+	if client.batch <= 0 {
+		client.batch = DefaultPreloadBatchSize
 	}
 
 	if options.WithCache {
@@ -68,7 +102,17 @@ func NewWithOptions(options *ClientOptions) (*Client, error) {
 
 // Exec executes a statement using given arguments.
 func (c *Client) Exec(ctx context.Context, query string, args ...interface{}) error {
-	_, err := c.node.ExecContext(ctx, query, args...)
+	done, err := c.enter()
+	if err != nil {
+		return errors.Wrap(err, "makroud: cannot execute query")
+	}
+	defer done()
+
+	ctx, cancel := withTimeout(ctx, c.timeout)
+	defer cancel()
+
+	_, err = c.node.ExecContext(ctx, query, args...)
+	c.observe(err, "exec", query)
 	if err != nil {
 		return errors.Wrap(err, "makroud: cannot execute query")
 	}
@@ -87,20 +131,46 @@ func (c *Client) MustExec(ctx context.Context, query string, args ...interface{}
 
 // Query executes a statement that returns rows using given arguments.
 func (c *Client) Query(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	done, err := c.enter()
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot execute query")
+	}
+
+	ctx, cancel := withTimeout(ctx, c.timeout)
+	release := func() {
+		cancel()
+		done()
+	}
+
 	rows, err := c.node.QueryContext(ctx, query, args...)
+	c.observe(err, "query", query)
 	if err != nil {
+		release()
 		return nil, errors.Wrap(err, "makroud: cannot execute query")
 	}
-	return wrapRows(rows), nil
+	return wrapRows(rows, c.obs, query, release), nil
 }
 
 // QueryRow executes a statement returning a single row.
 func (c *Client) QueryRow(ctx context.Context, query string, args ...interface{}) (Row, error) {
+	done, err := c.enter()
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot execute query")
+	}
+
+	ctx, cancel := withTimeout(ctx, c.timeout)
+	release := func() {
+		cancel()
+		done()
+	}
+
 	rows, err := c.node.QueryContext(ctx, query, args...)
+	c.observe(err, "query-row", query)
 	if err != nil {
+		release()
 		return nil, errors.Wrap(err, "makroud: cannot execute query")
 	}
-	return wrapRow(rows), nil
+	return wrapRow(rows, c.obs, query, release), nil
 }
 
 // MustQuery executes a statement that returns rows using given arguments.
@@ -116,11 +186,52 @@ func (c *Client) MustQuery(ctx context.Context, query string, args ...interface{
 // Prepare creates a prepared statement for later queries or executions.
 // Multiple queries or executions may be run concurrently from the returned statement.
 func (c *Client) Prepare(ctx context.Context, query string) (Statement, error) {
+	done, err := c.enter()
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot prepare statement")
+	}
+	defer done()
+
+	ctx, cancel := withTimeout(ctx, c.timeout)
+	defer cancel()
+
 	stmt, err := c.node.PrepareContext(ctx, query)
+	c.observe(err, "prepare", query)
 	if err != nil {
 		return nil, errors.Wrap(err, "makroud: cannot prepare statement")
 	}
-	return wrapStatement(stmt), nil
+	return wrapStatement(c, stmt, query), nil
+}
+
+// Explain returns the execution plan of given query, in JSON format.
+// The plan is obtained using a dedicated connection, outside of any transaction.
+func (c *Client) Explain(ctx context.Context, query string, args ...interface{}) ([]byte, error) {
+	done, err := c.enter()
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot explain query")
+	}
+	defer done()
+
+	db := c.node.DB()
+	if db == nil {
+		return nil, errors.Wrap(ErrInvalidDriver, "makroud: cannot obtain a connection for explain")
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot obtain a connection for explain")
+	}
+	defer close(c, conn, map[string]string{
+		"action": "explain",
+	})
+
+	plan := []byte{}
+	err = conn.QueryRowContext(ctx, fmt.Sprint("EXPLAIN (FORMAT JSON) ", query), args...).Scan(&plan)
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot explain query")
+	}
+
+	return plan, nil
 }
 
 // Begin starts a new transaction.
@@ -138,16 +249,50 @@ func (c *Client) Begin(ctx context.Context, opts ...*TxOptions) (Driver, error)
 		txOpts = opts[0]
 	}
 
+	nested := c.node.Tx() != nil
+
+	done, err := c.enter()
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot create a transaction")
+	}
+
 	node, err := c.node.BeginTx(ctx, txOpts)
+	c.observeBegin(err, nested, node)
 	if err != nil {
+		done()
 		return nil, errors.Wrap(err, "makroud: cannot create a transaction")
 	}
-	return wrapClient(c, node), nil
+
+	tx := wrapClient(c, node).(*Client)
+	if !nested {
+		// The transaction is an in-flight operation until its commit or rollback.
+		tx.done = done
+	}
+	return tx, nil
 }
 
 // Rollback rollbacks the associated transaction.
 func (c *Client) Rollback() error {
+	savepoint := c.node.SavepointName()
+	inTx := c.node.Tx() != nil
+
 	err := c.node.Rollback()
+	if c.done != nil {
+		c.done()
+	}
+	if c.obs != nil {
+		if inTx && savepoint != "" {
+			notifySavepointRollback(c.obs, err, map[string]string{
+				"action":    "rollback-savepoint",
+				"savepoint": savepoint,
+			})
+		} else {
+			notifyTransactionRollback(c.obs, err, map[string]string{
+				"action": "rollback",
+				"nested": strconv.FormatBool(c.node.IsNested()),
+			})
+		}
+	}
 	if err != nil {
 		return errors.Wrap(err, "makroud: cannot rollback transaction")
 	}
@@ -156,7 +301,26 @@ func (c *Client) Rollback() error {
 
 // Commit commits the associated transaction.
 func (c *Client) Commit() error {
+	savepoint := c.node.SavepointName()
+	inTx := c.node.Tx() != nil
+
 	err := c.node.Commit()
+	if c.done != nil {
+		c.done()
+	}
+	if c.obs != nil {
+		if inTx && savepoint != "" {
+			notifySavepointRelease(c.obs, err, map[string]string{
+				"action":    "release-savepoint",
+				"savepoint": savepoint,
+			})
+		} else {
+			notifyCommit(c.obs, err, map[string]string{
+				"action": "commit",
+				"nested": strconv.FormatBool(c.node.IsNested()),
+			})
+		}
+	}
 	if err != nil {
 		return errors.Wrap(err, "makroud: cannot commit transaction")
 	}
@@ -192,7 +356,14 @@ func (c *Client) Ping() error {
 
 // PingContext verifies that the underlying connection is healthy.
 func (c *Client) PingContext(ctx context.Context) error {
+	done, err := c.enter()
+	if err != nil {
+		return errors.Wrap(err, "makroud: cannot ping database")
+	}
+	defer done()
+
 	row, err := c.node.QueryContext(ctx, "SELECT true")
+	c.observe(err, "ping", "SELECT true")
 	if row != nil {
 		defer close(c, row, map[string]string{
 			"query":  "SELECT true;",
@@ -211,6 +382,32 @@ func (c *Client) DriverName() string {
 	return c.node.DriverName()
 }
 
+// Stats returns the statistics of the underlying connection pool.
+func (c *Client) Stats() sql.DBStats {
+	return c.node.Stats()
+}
+
+// InTransaction returns if the driver is bound to a transaction.
+func (c *Client) InTransaction() bool {
+	return c.node.Tx() != nil
+}
+
+// IsNested returns if the transaction of the driver is nested in another one.
+func (c *Client) IsNested() bool {
+	return c.node.IsNested()
+}
+
+// NestingDepth returns the number of transactions the driver is bound to: zero outside of a transaction, one
+// in a transaction and more than one in a nested transaction.
+func (c *Client) NestingDepth() int {
+	return c.node.NestingDepth()
+}
+
+// SavepointName returns the savepoint name of the nested transaction of the driver, if savepoints are enabled.
+func (c *Client) SavepointName() string {
+	return c.node.SavepointName()
+}
+
 // HasCache returns if current driver has an internal cache.
 func (c *Client) HasCache() bool {
 	return c.cache != nil
@@ -256,6 +453,52 @@ func (c *Client) Observer() Observer {
 	return c.obs
 }
 
+// SlowQueryThreshold returns the duration after which a query is reported as slow to the observer.
+// A zero duration means that slow query detection is disabled.
+func (c *Client) SlowQueryThreshold() time.Duration {
+	return c.slow
+}
+
+// HasSlowQueryExplain returns if the execution plan should be attached to a slow query report.
+func (c *Client) HasSlowQueryExplain() bool {
+	return c.explain
+}
+
+// NPlusOneThreshold returns how many times a query shape can be executed within a query scope before
+// being reported as a N+1 query to the observer.
+// A zero value means that N+1 detection is disabled.
+func (c *Client) NPlusOneThreshold() int {
+	return c.nplus
+}
+
+// Dialect returns the SQL dialect of the database engine.
+func (c *Client) Dialect() Dialect {
+	return c.dialect
+}
+
+// QueryTimeout returns the timeout applied on a query if its context has no deadline.
+// A zero duration means that there is no default timeout.
+func (c *Client) QueryTimeout() time.Duration {
+	return c.timeout
+}
+
+// TransactionTimeout returns the timeout applied on a transaction if its context has no deadline.
+// A zero duration means that there is no default timeout.
+func (c *Client) TransactionTimeout() time.Duration {
+	return c.txtime
+}
+
+// RetryPolicy returns the policy used to retry idempotent reads after a transient connection error.
+func (c *Client) RetryPolicy() RetryPolicy {
+	return c.retry
+}
+
+// PreloadBatchSize returns the maximum number of keys used by a single preload query.
+// A preload with more keys is split in multiple queries.
+func (c *Client) PreloadBatchSize() int {
+	return c.batch
+}
+
 // Entropy returns an entropy source, used for primary key generation (if required).
 //
 // WARNING: Please, do not use this method unless you know what you are doing.
@@ -266,11 +509,91 @@ func (c *Client) Entropy() io.Reader {
 // wrapClient creates a new Client using given database connection.
 func wrapClient(client *Client, connection Node) Driver {
 	return &Client{
-		node:  connection,
-		cache: client.cache,
-		log:   client.log,
-		rnd:   client.rnd,
+		node:    connection,
+		cache:   client.cache,
+		log:     client.log,
+		rnd:     client.rnd,
+		slow:    client.slow,
+		explain: client.explain,
+		nplus:   client.nplus,
+		timeout: client.timeout,
+		txtime:  client.txtime,
+		retry:   client.retry,
+		batch:   client.batch,
+		dialect: client.dialect,
+		state:   client.state,
+		life:    client.life,
+		obs:     client.obs,
+	}
+}
+
+// observe reports the outcome of given operation to the client observer.
+func (c *Client) observe(err error, action string, query string) {
+	if c.obs == nil {
+		return
+	}
+
+	c.track(err, action)
+	if err != nil {
+		notifyQueryError(c.obs, err, map[string]string{
+			"query":  query,
+			"action": action,
+		})
+	}
+}
+
+// track detects if the connection to the database has been lost, or if it's reachable again, using the
+// outcome of given operation.
+func (c *Client) track(err error, action string) {
+	if err != nil {
+		c.state.fail(err)
+		return
+	}
+
+	ok, cause := c.state.restore()
+	if ok {
+		notifyReconnect(c.obs, cause, map[string]string{
+			"action": action,
+		})
+	}
+}
+
+// observeBegin reports the outcome of a transaction creation to the client observer.
+func (c *Client) observeBegin(err error, nested bool, node Node) {
+	if c.obs == nil {
+		return
+	}
+
+	if !nested {
+		c.track(err, "begin")
+		notifyBegin(c.obs, err, map[string]string{
+			"action": "begin",
+			"nested": "false",
+		})
+		return
+	}
+
+	// A nested transaction can only fail while creating its savepoint.
+	if err != nil {
+		notifySavepointCreate(c.obs, err, map[string]string{
+			"action": "create-savepoint",
+		})
+		return
+	}
+
+	savepoint := node.SavepointName()
+	if savepoint != "" {
+		notifySavepointCreate(c.obs, nil, map[string]string{
+			"action":    "create-savepoint",
+			"savepoint": savepoint,
+		})
+		return
 	}
+
+	notifyBegin(c.obs, nil, map[string]string{
+		"action": "begin",
+		"nested": "true",
+	})
 }
 
 // getNodeForClient returns the required node for client creation.
@@ -279,20 +602,66 @@ func getNodeForClient(options *ClientOptions) (Node, error) {
 		return options.Node, nil
 	}
 
-	_ = pq.Driver{}
+	connector, err := getConnectorForClient(options)
+	if err != nil {
+		return nil, err
+	}
 
-	node, err := Connect(ClientDriver, options.String())
+	node, err := ConnectWithConnector(getDriverName(options), connector)
 	if err != nil {
-		return nil, errors.Wrapf(err, "makroud: cannot connect to %s server", ClientDriver)
+		return nil, errors.Wrapf(err, "makroud: cannot connect to %s server", getDriverName(options))
 	}
 
 	node.SetMaxIdleConns(options.MaxIdleConnections)
 	node.SetMaxOpenConns(options.MaxOpenConnections)
+	node.SetConnMaxLifetime(options.ConnMaxLifetime)
+	node.SetConnMaxIdleTime(options.ConnMaxIdleTime)
 	node.EnableSavepoint(options.SavepointEnabled)
 
 	return node, nil
 }
 
+// getConnectorForClient returns the driver connector used for client creation.
+func getConnectorForClient(options *ClientOptions) (driver.Connector, error) {
+	connector, err := newConnector(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapped driver.Connector = connector
+
+	if options.Credentials != nil {
+		wrapped = &credentialsConnector{
+			options:  *options,
+			provider: options.Credentials,
+			driver:   connector.Driver(),
+		}
+	}
+
+	if len(options.InitStatements) > 0 || options.OnConnect != nil {
+		wrapped = &initConnector{
+			wrapped:    wrapped,
+			statements: options.InitStatements,
+			callback:   options.OnConnect,
+		}
+	}
+
+	if len(options.Hooks) > 0 {
+		wrapped = hooks.Wrap(wrapped, options.Hooks...)
+	}
+
+	return wrapped, nil
+}
+
+// getDialectForClient returns the SQL dialect used for client creation.
+func getDialectForClient(options *ClientOptions) Dialect {
+	if options.Dialect != nil {
+		return options.Dialect
+	}
+
+	return PostgresDialect{}
+}
+
 // getEntropyForClient returns the required entropy source for client creation.
 func getEntropyForClient(options *ClientOptions) io.Reader {
 	if options.Entropy != nil {
@@ -304,13 +673,17 @@ func getEntropyForClient(options *ClientOptions) io.Reader {
 
 // A stmtWrapper wraps a statement from sql.
 type stmtWrapper struct {
-	stmt *sql.Stmt
+	stmt   *sql.Stmt
+	client *Client
+	query  string
 }
 
 // wrapStatement creates a new Statement using given statement.
-func wrapStatement(stmt *sql.Stmt) Statement {
+func wrapStatement(client *Client, stmt *sql.Stmt, query string) Statement {
 	return &stmtWrapper{
-		stmt: stmt,
+		stmt:   stmt,
+		client: client,
+		query:  query,
 	}
 }
 
@@ -325,7 +698,17 @@ func (w *stmtWrapper) Close() error {
 
 // Exec executes this statement using the struct passed.
 func (w *stmtWrapper) Exec(ctx context.Context, args ...interface{}) error {
-	_, err := w.stmt.ExecContext(ctx, args...)
+	done, err := w.client.enter()
+	if err != nil {
+		return errors.Wrap(err, "makroud: cannot execute statement")
+	}
+	defer done()
+
+	ctx, cancel := withTimeout(ctx, w.client.timeout)
+	defer cancel()
+
+	_, err = w.stmt.ExecContext(ctx, args...)
+	w.client.observe(err, "statement-exec", w.query)
 	if err != nil {
 		return errors.Wrap(err, "makroud: cannot execute statement")
 	}
@@ -334,32 +717,65 @@ func (w *stmtWrapper) Exec(ctx context.Context, args ...interface{}) error {
 
 // QueryRow executes this statement returning a single row.
 func (w *stmtWrapper) QueryRow(ctx context.Context, args ...interface{}) (Row, error) {
+	done, err := w.client.enter()
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot execute statement")
+	}
+
+	ctx, cancel := withTimeout(ctx, w.client.timeout)
+	release := func() {
+		cancel()
+		done()
+	}
+
 	rows, err := w.stmt.QueryContext(ctx, args...)
+	w.client.observe(err, "statement-query-row", w.query)
 	if err != nil {
+		release()
 		return nil, errors.Wrap(err, "makroud: cannot execute statement")
 	}
-	return wrapRow(rows), nil
+	return wrapRow(rows, w.client.obs, w.query, release), nil
 }
 
 // QueryRows executes this statement returning a list of rows.
 func (w *stmtWrapper) QueryRows(ctx context.Context, args ...interface{}) (Rows, error) {
+	done, err := w.client.enter()
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot execute statement")
+	}
+
+	ctx, cancel := withTimeout(ctx, w.client.timeout)
+	release := func() {
+		cancel()
+		done()
+	}
+
 	rows, err := w.stmt.QueryContext(ctx, args...)
+	w.client.observe(err, "statement-query-rows", w.query)
 	if err != nil {
+		release()
 		return nil, errors.Wrap(err, "makroud: cannot execute statement")
 	}
-	return wrapRows(rows), nil
+	return wrapRows(rows, w.client.obs, w.query, release), nil
 }
 
 // A rowWrapper is a reimplementation of sql.Row in order to gain access to the underlying
 // Columns() function.
 type rowWrapper struct {
-	rows *sql.Rows
+	rows  *sql.Rows
+	obs   Observer
+	query string
+	done  func()
 }
 
 // wrapRow creates a new Row using given rows from sql.
-func wrapRow(rows *sql.Rows) Row {
+// The done callback is executed once the row is scanned.
+func wrapRow(rows *sql.Rows, obs Observer, query string, done func()) Row {
 	return &rowWrapper{
-		rows: rows,
+		rows:  rows,
+		obs:   obs,
+		query: query,
+		done:  done,
 	}
 }
 
@@ -387,6 +803,12 @@ func (r *rowWrapper) Columns() ([]string, error) {
 func (r *rowWrapper) Scan(dest ...interface{}) error {
 	err := r.scan(dest...)
 	if err != nil {
+		if r.obs != nil && !IsErrNoRows(err) {
+			notifyScanError(r.obs, err, map[string]string{
+				"query":  r.query,
+				"action": "scan-row",
+			})
+		}
 		return errors.Wrap(err, "makroud: cannot scan given values")
 	}
 	return nil
@@ -397,6 +819,7 @@ func (r *rowWrapper) scan(dest ...interface{}) error {
 	// Discard sql.RawBytes to avoid weird issues with the SQL driver and memory management.
 	defer func() {
 		_ = r.rows.Close()
+		r.done()
 	}()
 	for i := range dest {
 		_, ok := dest[i].(*sql.RawBytes)
@@ -424,13 +847,20 @@ func (r *rowWrapper) scan(dest ...interface{}) error {
 
 // A rowsWrapper wraps a rows from sql.
 type rowsWrapper struct {
-	rows *sql.Rows
+	rows  *sql.Rows
+	obs   Observer
+	query string
+	done  func()
 }
 
 // wrapRow creates a new Rows using given rows from sql.
-func wrapRows(rows *sql.Rows) Rows {
+// The done callback is executed once the rows are closed or exhausted.
+func wrapRows(rows *sql.Rows, obs Observer, query string, done func()) Rows {
 	return &rowsWrapper{
-		rows: rows,
+		rows:  rows,
+		obs:   obs,
+		query: query,
+		done:  done,
 	}
 }
 
@@ -440,13 +870,19 @@ func wrapRows(rows *sql.Rows) Rows {
 // Err should be consulted to distinguish between the two cases.
 // Every call to Scan, even the first one, must be preceded by a call to Next.
 func (r *rowsWrapper) Next() bool {
-	return r.rows.Next()
+	next := r.rows.Next()
+	if !next {
+		r.done()
+	}
+	return next
 }
 
 // Close closes the Rows, preventing further enumeration/iteration.
 // If Next is called and returns false and there are no further result sets, the Rows are closed automatically
 // and it will suffice to check the result of Err.
 func (r *rowsWrapper) Close() error {
+	defer r.done()
+
 	err := r.rows.Close()
 	if err != nil {
 		return errors.Wrap(err, "makroud: cannot close rows")
@@ -493,6 +929,12 @@ func (r *rowsWrapper) Columns() ([]string, error) {
 func (r *rowsWrapper) Scan(dest ...interface{}) error {
 	err := r.rows.Scan(dest...)
 	if err != nil {
+		if r.obs != nil {
+			notifyScanError(r.obs, err, map[string]string{
+				"query":  r.query,
+				"action": "scan-rows",
+			})
+		}
 		return errors.Wrap(err, "makroud: cannot scan given values")
 	}
 	return nil
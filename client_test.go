@@ -3,14 +3,17 @@ package makroud_test
 import (
 	"context"
 	"database/sql"
+	sqldriver "database/sql/driver"
 	"testing"
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"github.com/ulule/loukoum/v3"
 
 	"github.com/ulule/makroud"
+	"github.com/ulule/makroud/hooks"
 )
 
 func TestClient_New(t *testing.T) {
@@ -30,6 +33,237 @@ func TestClient_New(t *testing.T) {
 	is.Empty(driver)
 }
 
+func TestClient_WithHooks(t *testing.T) {
+	queries := []string{}
+	subscriber := hooks.Hooks{
+		BeforeExec: func(ctx context.Context, query string, args []sqldriver.NamedValue) context.Context {
+			queries = append(queries, query)
+			return ctx
+		},
+	}
+
+	Setup(t, makroud.WithHooks(subscriber))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		queries = []string{}
+		stmt := `UPDATE ztp_human SET name = $1 WHERE name = $2`
+
+		err := driver.Exec(ctx, stmt, "Delora", "Maria")
+		is.NoError(err)
+		is.Equal([]string{stmt}, queries)
+	})
+}
+
+func TestClient_InitStatements(t *testing.T) {
+	connections := 0
+	callback := func(ctx context.Context, conn sqldriver.Conn) error {
+		connections++
+		return makroud.ExecConn(ctx, conn, "SET lock_timeout = 3000")
+	}
+
+	Setup(t, makroud.InitStatements(
+		"SET application_name = 'zootopia'",
+		"SET search_path TO public",
+	), makroud.OnConnect(callback))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		is.True(connections > 0)
+
+		name := ""
+		err := makroud.RawExec(ctx, driver, "SHOW application_name", &name)
+		is.NoError(err)
+		is.Equal("zootopia", name)
+
+		timeout := ""
+		err = makroud.RawExec(ctx, driver, "SHOW lock_timeout", &timeout)
+		is.NoError(err)
+		is.Equal("3s", timeout)
+	})
+
+	is := require.New(t)
+	driver, err := makroud.New(Options(makroud.InitStatements("SET unknown_parameter = 1"))...)
+	is.Error(err)
+	is.Empty(driver)
+}
+
+func TestClient_CredentialsProvider(t *testing.T) {
+	reference := ClientOptions()
+
+	calls := 0
+	provider := func(ctx context.Context) (makroud.Credentials, error) {
+		calls++
+		return makroud.Credentials{
+			User:     reference.User,
+			Password: reference.Password,
+		}, nil
+	}
+
+	Setup(t, makroud.Password("invalid"), makroud.WithCredentialsProvider(provider))(func(driver makroud.Driver) {
+		is := require.New(t)
+
+		is.True(calls > 0)
+		is.NoError(driver.Ping())
+	})
+
+	is := require.New(t)
+	failure := errors.New("vault: token expired")
+	driver, err := makroud.New(Options(makroud.WithCredentialsProvider(
+		func(ctx context.Context) (makroud.Credentials, error) {
+			return makroud.Credentials{}, failure
+		},
+	))...)
+	is.Error(err)
+	is.Equal(failure, errors.Cause(err))
+	is.Empty(driver)
+}
+
+func TestClient_WithConnector(t *testing.T) {
+	dsn := ""
+	factory := func(value string) (sqldriver.Connector, error) {
+		dsn = value
+		return pq.NewConnector(value)
+	}
+
+	Setup(t, makroud.WithConnector(factory))(func(driver makroud.Driver) {
+		is := require.New(t)
+
+		is.NotEmpty(dsn)
+		is.Equal(makroud.ClientDriver, driver.DriverName())
+		is.NoError(driver.Ping())
+	})
+
+	is := require.New(t)
+	driver, err := makroud.New(Options(makroud.WithDriver("unknown"))...)
+	is.Error(err)
+	is.Empty(driver)
+}
+
+func TestClient_Shutdown(t *testing.T) {
+	is := require.New(t)
+	ctx := context.Background()
+
+	driver, err := makroud.New(Options()...)
+	is.NoError(err)
+
+	tx, err := driver.Begin(ctx)
+	is.NoError(err)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- driver.Shutdown(ctx)
+	}()
+
+	// Wait for the shutdown to be effective.
+	is.Eventually(func() bool {
+		return errors.Cause(driver.Ping()) == makroud.ErrShutdown
+	}, 5*time.Second, 10*time.Millisecond)
+
+	err = driver.Exec(ctx, "SELECT 1")
+	is.Error(err)
+	is.Equal(makroud.ErrShutdown, errors.Cause(err))
+
+	_, err = driver.Begin(ctx)
+	is.Error(err)
+	is.Equal(makroud.ErrShutdown, errors.Cause(err))
+
+	// The transaction in flight must be able to finish.
+	err = tx.Exec(ctx, "SELECT 1")
+	is.NoError(err)
+
+	select {
+	case <-shutdown:
+		is.FailNow("shutdown must wait for in-flight transactions")
+	default:
+	}
+
+	err = tx.Commit()
+	is.NoError(err)
+
+	select {
+	case err = <-shutdown:
+		is.NoError(err)
+	case <-time.After(5 * time.Second):
+		is.FailNow("shutdown must return once in-flight transactions are done")
+	}
+
+	driver, err = makroud.New(Options()...)
+	is.NoError(err)
+
+	rows, err := driver.Query(ctx, "SELECT 1")
+	is.NoError(err)
+
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	err = driver.Shutdown(timeout)
+	is.Error(err)
+	is.Equal(context.DeadlineExceeded, errors.Cause(err))
+	_ = rows.Close()
+
+	selector, err := makroud.NewSelector(map[string]*makroud.ClientOptions{
+		makroud.MasterSelector: ClientOptions(),
+	})
+	is.NoError(err)
+
+	_, err = selector.Using(makroud.MasterSelector)
+	is.NoError(err)
+
+	err = selector.Shutdown(ctx)
+	is.NoError(err)
+
+	_, err = selector.Using(makroud.MasterSelector)
+	is.Error(err)
+	is.Equal(makroud.ErrShutdown, errors.Cause(err))
+}
+
+func TestClient_QueryTimeout(t *testing.T) {
+	Setup(t, makroud.DefaultQueryTimeout(50*time.Millisecond))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		is.Equal(50*time.Millisecond, driver.QueryTimeout())
+
+		err := driver.Exec(ctx, "SELECT pg_sleep(0.5)")
+		is.Error(err)
+
+		rows, err := driver.Query(ctx, "SELECT pg_sleep(0.5)")
+		if err == nil {
+			is.False(rows.Next())
+			is.Error(rows.Err())
+			is.NoError(rows.Close())
+		}
+
+		err = driver.Exec(makroud.WithoutTimeout(ctx), "SELECT pg_sleep(0.1)")
+		is.NoError(err)
+
+		timeout, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		err = driver.Exec(timeout, "SELECT pg_sleep(0.1)")
+		is.NoError(err)
+
+		row, err := driver.QueryRow(ctx, "SELECT 1")
+		is.NoError(err)
+		value := 0
+		is.NoError(row.Scan(&value))
+		is.Equal(1, value)
+
+		stmt, err := driver.Prepare(ctx, "SELECT pg_sleep($1)")
+		is.NoError(err)
+		defer func() {
+			is.NoError(stmt.Close())
+		}()
+
+		err = stmt.Exec(ctx, 0.5)
+		is.Error(err)
+
+		err = stmt.Exec(makroud.WithoutTimeout(ctx), 0.1)
+		is.NoError(err)
+	})
+}
+
 func TestClient_Exec(t *testing.T) {
 	Setup(t)(func(driver makroud.Driver) {
 		ctx := context.Background()
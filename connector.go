@@ -0,0 +1,219 @@
+package makroud
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// ConnectCallback is executed on every new physical connection, before its first use.
+// If it returns an error, the connection is closed and discarded.
+type ConnectCallback func(ctx context.Context, conn driver.Conn) error
+
+// initConnector wraps a driver.Connector to initialize every new physical connection, using a list of statements
+// and an optional callback.
+type initConnector struct {
+	wrapped    driver.Connector
+	statements []string
+	callback   ConnectCallback
+}
+
+// Connect implements database/sql/driver.Connector.
+func (connector *initConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := connector.wrapped.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = connector.initialize(ctx, conn)
+	if err != nil {
+		// the connection has been opened within this function, we must close it
+		// on error.
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Driver implements database/sql/driver.Connector.
+func (connector *initConnector) Driver() driver.Driver {
+	return connector.wrapped.Driver()
+}
+
+func (connector *initConnector) initialize(ctx context.Context, conn driver.Conn) error {
+	for _, statement := range connector.statements {
+		err := ExecConn(ctx, conn, statement)
+		if err != nil {
+			return errors.Wrapf(err, "makroud: cannot execute init statement: %s", statement)
+		}
+	}
+
+	if connector.callback != nil {
+		err := connector.callback(ctx, conn)
+		if err != nil {
+			return errors.Wrap(err, "makroud: cannot initialize connection")
+		}
+	}
+
+	return nil
+}
+
+// ExecConn executes a statement without arguments on given physical connection.
+// It's useful to initialize a connection from a ConnectCallback.
+func ExecConn(ctx context.Context, conn driver.Conn, query string) error {
+	execer, ok := conn.(driver.ExecerContext)
+	if ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		if err != driver.ErrSkip {
+			return err
+		}
+	}
+
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	preparer, ok := conn.(driver.ConnPrepareContext)
+	if ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.Prepare(query)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	stmtExecer, ok := stmt.(driver.StmtExecContext)
+	if ok {
+		_, err = stmtExecer.ExecContext(ctx, nil)
+		return err
+	}
+
+	// nolint:staticcheck
+	_, err = stmt.Exec(nil)
+	return err
+}
+
+// Credentials defines the authentication used to open a physical connection.
+type Credentials struct {
+	User     string
+	Password string
+}
+
+// CredentialsProvider returns the credentials to use for a new physical connection.
+// It's executed every time a connection is opened, so short-lived credentials, such as IAM or Vault tokens,
+// can be renewed without creating a new Client.
+type CredentialsProvider func(ctx context.Context) (Credentials, error)
+
+// credentialsConnector is a driver.Connector that creates a new connection using credentials retrieved from a
+// provider.
+type credentialsConnector struct {
+	options  ClientOptions
+	provider CredentialsProvider
+	driver   driver.Driver
+}
+
+// Connect implements database/sql/driver.Connector.
+func (connector *credentialsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	credentials, err := connector.provider(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "makroud: cannot retrieve credentials")
+	}
+
+	options := connector.options
+	if credentials.User != "" {
+		options.User = credentials.User
+	}
+	options.Password = credentials.Password
+
+	wrapped, err := newConnector(&options)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapped.Connect(ctx)
+}
+
+// Driver implements database/sql/driver.Connector.
+func (connector *credentialsConnector) Driver() driver.Driver {
+	return connector.driver
+}
+
+// ConnectorFactory returns a driver.Connector for given connection string.
+type ConnectorFactory func(dsn string) (driver.Connector, error)
+
+// newConnector returns a driver.Connector for given options, using the configured SQL driver.
+func newConnector(options *ClientOptions) (driver.Connector, error) {
+	name := getDriverName(options)
+
+	var (
+		connector driver.Connector
+		err       error
+	)
+	switch {
+	case options.Connector != nil:
+		connector, err = options.Connector(options.String())
+	case name == ClientDriver:
+		connector, err = pq.NewConnector(options.String())
+	default:
+		connector, err = openConnector(name, options.String())
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "makroud: cannot create %s connector", name)
+	}
+
+	return connector, nil
+}
+
+// openConnector returns a driver.Connector for given connection string, using a driver registered in
+// database/sql with given name.
+func openConnector(name string, dsn string) (driver.Connector, error) {
+	db, err := sql.Open(name, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	instance := db.Driver()
+	_ = db.Close()
+
+	opener, ok := instance.(driver.DriverContext)
+	if ok {
+		return opener.OpenConnector(dsn)
+	}
+
+	return &dsnConnector{
+		driver: instance,
+		dsn:    dsn,
+	}, nil
+}
+
+// dsnConnector is a driver.Connector for drivers that don't implement driver.DriverContext.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+// Connect implements database/sql/driver.Connector.
+func (connector *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return connector.driver.Open(connector.dsn)
+}
+
+// Driver implements database/sql/driver.Connector.
+func (connector *dsnConnector) Driver() driver.Driver {
+	return connector.driver
+}
+
+// getDriverName returns the SQL driver name used for client creation.
+func getDriverName(options *ClientOptions) string {
+	if options.DriverName != "" {
+		return options.DriverName
+	}
+	return ClientDriver
+}
@@ -67,10 +67,15 @@ func archive(ctx context.Context, driver Driver, model Model) error {
 		return errors.Wrapf(err, "%T cannot be archived", model)
 	}
 
+	dialect := getDialect(driver)
+
 	builder := loukoum.Update(schema.TableName()).
-		Set(loukoum.Pair(schema.DeletedKeyName(), loukoum.Raw("NOW()"))).
-		Where(loukoum.Condition(pk.ColumnName()).Equal(id)).
-		Returning(schema.DeletedKeyName())
+		Set(loukoum.Pair(schema.DeletedKeyName(), loukoum.Raw(dialect.CurrentTimestamp()))).
+		Where(loukoum.Condition(pk.ColumnName()).Equal(id))
+
+	if dialect.SupportsReturning() {
+		builder = builder.Returning(schema.DeletedKeyName())
+	}
 
 	return Exec(ctx, driver, builder)
 }
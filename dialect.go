@@ -0,0 +1,205 @@
+package makroud
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect defines the SQL syntax specific to a database engine.
+//
+// Queries generated by loukoum use PostgreSQL syntax, they are rewritten by the dialect of the driver before
+// their execution.
+type Dialect interface {
+	// Name returns the dialect name.
+	Name() string
+	// Placeholder returns the bind parameter for given position, starting at one.
+	Placeholder(position int) string
+	// Rebind rewrites given query using PostgreSQL bind parameters ($1, $2, ...) to the dialect ones.
+	Rebind(query string) string
+	// CurrentTimestamp returns the expression that evaluates to the current date and time.
+	CurrentTimestamp() string
+	// SupportsReturning returns if the dialect supports a RETURNING clause on INSERT, UPDATE and DELETE.
+	SupportsReturning() bool
+	// Quote returns given identifier quoted, so it can be used safely in a query.
+	Quote(identifier string) string
+	// Savepoint returns the statement that creates a savepoint with given name.
+	Savepoint(name string) string
+	// ReleaseSavepoint returns the statement that releases the savepoint with given name.
+	ReleaseSavepoint(name string) string
+	// RollbackToSavepoint returns the statement that rollbacks to the savepoint with given name.
+	RollbackToSavepoint(name string) string
+}
+
+// PostgresDialect is the PostgreSQL dialect, used by default.
+type PostgresDialect struct{}
+
+// Name returns the dialect name.
+func (PostgresDialect) Name() string {
+	return "postgres"
+}
+
+// Placeholder returns the bind parameter for given position, starting at one.
+func (PostgresDialect) Placeholder(position int) string {
+	return "$" + strconv.Itoa(position)
+}
+
+// Rebind rewrites given query using PostgreSQL bind parameters ($1, $2, ...) to the dialect ones.
+func (PostgresDialect) Rebind(query string) string {
+	return query
+}
+
+// CurrentTimestamp returns the expression that evaluates to the current date and time.
+func (PostgresDialect) CurrentTimestamp() string {
+	return "NOW()"
+}
+
+// SupportsReturning returns if the dialect supports a RETURNING clause on INSERT, UPDATE and DELETE.
+func (PostgresDialect) SupportsReturning() bool {
+	return true
+}
+
+// Quote returns given identifier quoted, so it can be used safely in a query.
+func (PostgresDialect) Quote(identifier string) string {
+	return quoteIdentifier(identifier, '"')
+}
+
+// Savepoint returns the statement that creates a savepoint with given name.
+func (dialect PostgresDialect) Savepoint(name string) string {
+	return "SAVEPOINT " + dialect.Quote(name)
+}
+
+// ReleaseSavepoint returns the statement that releases the savepoint with given name.
+func (dialect PostgresDialect) ReleaseSavepoint(name string) string {
+	return "RELEASE SAVEPOINT " + dialect.Quote(name)
+}
+
+// RollbackToSavepoint returns the statement that rollbacks to the savepoint with given name.
+func (dialect PostgresDialect) RollbackToSavepoint(name string) string {
+	return "ROLLBACK TO SAVEPOINT " + dialect.Quote(name)
+}
+
+// SQLiteDialect is the SQLite dialect.
+// It requires SQLite 3.35.0 or later for RETURNING support.
+//
+// Since the connection string of SQLite drivers is not a PostgreSQL URL, the node should be created using
+// WithNode or WithConnector.
+type SQLiteDialect struct{}
+
+// Name returns the dialect name.
+func (SQLiteDialect) Name() string {
+	return "sqlite"
+}
+
+// Placeholder returns the bind parameter for given position, starting at one.
+func (SQLiteDialect) Placeholder(position int) string {
+	return "?" + strconv.Itoa(position)
+}
+
+// Rebind rewrites given query using PostgreSQL bind parameters ($1, $2, ...) to the dialect ones.
+func (dialect SQLiteDialect) Rebind(query string) string {
+	return rebindQuery(query, dialect.Placeholder)
+}
+
+// CurrentTimestamp returns the expression that evaluates to the current date and time.
+func (SQLiteDialect) CurrentTimestamp() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+// SupportsReturning returns if the dialect supports a RETURNING clause on INSERT, UPDATE and DELETE.
+func (SQLiteDialect) SupportsReturning() bool {
+	return true
+}
+
+// Quote returns given identifier quoted, so it can be used safely in a query.
+func (SQLiteDialect) Quote(identifier string) string {
+	return quoteIdentifier(identifier, '"')
+}
+
+// Savepoint returns the statement that creates a savepoint with given name.
+func (dialect SQLiteDialect) Savepoint(name string) string {
+	return "SAVEPOINT " + dialect.Quote(name)
+}
+
+// ReleaseSavepoint returns the statement that releases the savepoint with given name.
+func (dialect SQLiteDialect) ReleaseSavepoint(name string) string {
+	return "RELEASE SAVEPOINT " + dialect.Quote(name)
+}
+
+// RollbackToSavepoint returns the statement that rollbacks to the savepoint with given name.
+func (dialect SQLiteDialect) RollbackToSavepoint(name string) string {
+	return "ROLLBACK TO SAVEPOINT " + dialect.Quote(name)
+}
+
+// getDialect returns the dialect of given driver.
+func getDialect(driver Driver) Dialect {
+	if driver == nil {
+		return PostgresDialect{}
+	}
+	dialect := driver.Dialect()
+	if dialect == nil {
+		return PostgresDialect{}
+	}
+	return dialect
+}
+
+// quoteIdentifier quotes every part of given identifier, such as "schema"."table".
+func quoteIdentifier(identifier string, quote byte) string {
+	parts := strings.Split(identifier, ".")
+	for i := range parts {
+		escaped := strings.Replace(parts[i], string(quote), string([]byte{quote, quote}), -1)
+		parts[i] = string(quote) + escaped + string(quote)
+	}
+	return strings.Join(parts, ".")
+}
+
+// rebindQuery replaces every PostgreSQL bind parameter in given query with the placeholder of the same position.
+// String literals, quoted identifiers and comments are left untouched.
+func rebindQuery(query string, placeholder func(position int) string) string {
+	buffer := strings.Builder{}
+	buffer.Grow(len(query))
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				buffer.WriteString(query[i:])
+				return buffer.String()
+			}
+			buffer.WriteString(query[i : i+end+2])
+			i += end + 1
+
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				buffer.WriteString(query[i:])
+				return buffer.String()
+			}
+			buffer.WriteString(query[i : i+end+1])
+			i += end
+
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			end := i + 1
+			for end < len(query) && isDigit(query[end]) {
+				end++
+			}
+			position, err := strconv.Atoi(query[i+1 : end])
+			if err != nil {
+				buffer.WriteString(query[i:end])
+			} else {
+				buffer.WriteString(placeholder(position))
+			}
+			i = end - 1
+
+		default:
+			buffer.WriteByte(c)
+		}
+	}
+
+	return buffer.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,123 @@
+package makroud_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+func TestDialect_Postgres(t *testing.T) {
+	is := require.New(t)
+
+	dialect := makroud.PostgresDialect{}
+	query := `SELECT id FROM ztp_owl WHERE name = $1 AND feather_color = $2`
+
+	is.Equal("postgres", dialect.Name())
+	is.Equal("$3", dialect.Placeholder(3))
+	is.Equal(query, dialect.Rebind(query))
+	is.Equal("NOW()", dialect.CurrentTimestamp())
+	is.True(dialect.SupportsReturning())
+	is.Equal(`"public"."ztp_owl"`, dialect.Quote("public.ztp_owl"))
+	is.Equal(`SAVEPOINT "sp_1"`, dialect.Savepoint("sp_1"))
+	is.Equal(`RELEASE SAVEPOINT "sp_1"`, dialect.ReleaseSavepoint("sp_1"))
+	is.Equal(`ROLLBACK TO SAVEPOINT "sp_1"`, dialect.RollbackToSavepoint("sp_1"))
+}
+
+func TestDialect_SQLite(t *testing.T) {
+	is := require.New(t)
+
+	dialect := makroud.SQLiteDialect{}
+
+	is.Equal("sqlite", dialect.Name())
+	is.Equal("?3", dialect.Placeholder(3))
+	is.Equal("CURRENT_TIMESTAMP", dialect.CurrentTimestamp())
+	is.True(dialect.SupportsReturning())
+	is.Equal(`"ztp_owl"`, dialect.Quote("ztp_owl"))
+	is.Equal(`"weird""name"`, dialect.Quote(`weird"name`))
+	is.Equal(`SAVEPOINT "sp_1"`, dialect.Savepoint("sp_1"))
+
+	query, args := loukoum.Select("id").From("ztp_owl").
+		Where(loukoum.Condition("name").In("Blake", "Hedwig")).
+		And(loukoum.Condition("feather_color").Equal("white")).
+		Query()
+
+	is.Equal("SELECT id FROM ztp_owl WHERE ((name IN (?1, ?2)) AND (feather_color = ?3))", dialect.Rebind(query))
+	is.Len(args, 3)
+
+	is.Equal(
+		`SELECT '$1', "$2" FROM ztp_owl WHERE id = ?1 -- $2`+"\n"+`AND price = ?2`,
+		dialect.Rebind(`SELECT '$1', "$2" FROM ztp_owl WHERE id = $1 -- $2`+"\n"+`AND price = $2`),
+	)
+}
+
+// withoutReturningDialect is a PostgreSQL dialect that doesn't support RETURNING clauses.
+type withoutReturningDialect struct {
+	makroud.PostgresDialect
+}
+
+func (withoutReturningDialect) Name() string {
+	return "postgres-without-returning"
+}
+
+func (withoutReturningDialect) SupportsReturning() bool {
+	return false
+}
+
+func TestDialect_WithoutReturning(t *testing.T) {
+	Setup(t, makroud.WithDialect(withoutReturningDialect{}), makroud.EnableSavepoint())(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		cat := &Cat{Name: "Hemingway"}
+		err := makroud.Save(ctx, driver, cat)
+		is.NoError(err)
+		is.NotEmpty(cat.ID)
+		is.False(cat.CreatedAt.IsZero())
+		is.False(cat.UpdatedAt.IsZero())
+
+		query := loukoum.Select("*").From("ztp_cat").Where(loukoum.Condition("id").Equal(cat.ID))
+		last := &Cat{}
+		err = makroud.Exec(ctx, driver, query, last)
+		is.NoError(err)
+		is.Equal("Hemingway", last.Name)
+		is.Equal(cat.CreatedAt.Unix(), last.CreatedAt.Unix())
+
+		err = makroud.Transaction(ctx, driver, nil, func(tx makroud.Driver) error {
+			return makroud.Transaction(ctx, tx, nil, func(tx makroud.Driver) error {
+				is.NotEmpty(tx.SavepointName())
+				cat.Name = "Papa"
+				return makroud.Save(ctx, tx, cat)
+			})
+		})
+		is.NoError(err)
+
+		last = &Cat{}
+		err = makroud.Exec(ctx, driver, query, last)
+		is.NoError(err)
+		is.Equal("Papa", last.Name)
+
+		err = makroud.Archive(ctx, driver, cat)
+		is.NoError(err)
+
+		count, err := makroud.Count(ctx, driver, loukoum.Select("COUNT(*)").From("ztp_cat").
+			Where(loukoum.Condition("id").Equal(cat.ID)).And(loukoum.Condition("deleted_at").IsNull(false)))
+		is.NoError(err)
+		is.Equal(int64(1), count)
+
+		err = makroud.Delete(ctx, driver, cat)
+		is.NoError(err)
+
+		count, err = makroud.Count(ctx, driver, loukoum.Select("COUNT(*)").From("ztp_cat").
+			Where(loukoum.Condition("id").Equal(cat.ID)))
+		is.NoError(err)
+		is.Equal(int64(0), count)
+
+		owl := &Owl{Name: "Blake"}
+		err = makroud.Save(ctx, driver, owl)
+		is.Error(err)
+	})
+}
@@ -2,6 +2,12 @@ package makroud
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
 )
 
 // Makroud general errors.
@@ -22,6 +28,8 @@ var (
 	ErrModelRequired = fmt.Errorf("a model is required")
 	// ErrSchemaColumnRequired is returned when we cannot find a column in current schema.
 	ErrSchemaColumnRequired = fmt.Errorf("cannot find column in schema")
+	// ErrSchemaInvalidAssociation is returned when we cannot find an association in current schema.
+	ErrSchemaInvalidAssociation = fmt.Errorf("cannot find association in schema")
 	// ErrSchemaCreatedKey is returned when we cannot find a created key in given schema.
 	ErrSchemaCreatedKey = fmt.Errorf("cannot find created key in schema")
 	// ErrSchemaUpdatedKey is returned when we cannot find a updated key in given schema.
@@ -42,4 +50,191 @@ var (
 	ErrSliceOfScalarMultipleColumns = fmt.Errorf("slice of scalar with multiple columns")
 	// ErrCommitNotInTransaction is returned when using commit outside of a transaction.
 	ErrCommitNotInTransaction = fmt.Errorf("cannot commit outside of a transaction")
+	// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+	ErrInvalidCursor = fmt.Errorf("invalid cursor")
+	// ErrShutdown is returned when a new operation is requested on a driver that is shutting down.
+	ErrShutdown = fmt.Errorf("driver is shutting down")
+)
+
+// SQLSTATE codes of PostgreSQL errors.
+const (
+	CodeUniqueViolation      = "23505"
+	CodeForeignKeyViolation  = "23503"
+	CodeNotNullViolation     = "23502"
+	CodeCheckViolation       = "23514"
+	CodeSerializationFailure = "40001"
+	CodeQueryCanceled        = "57014"
 )
+
+// DriverError is an error reported by the database server, independently of the SQL driver used.
+type DriverError struct {
+	// Code is the five-character SQLSTATE code of the error.
+	Code       string
+	Message    string
+	Detail     string
+	Schema     string
+	Table      string
+	Column     string
+	Constraint string
+	// Field is the name of the model field mapped to the violated column, if the error has been returned by
+	// an operation on this model, such as Save.
+	Field string
+	// Err is the original error returned by the SQL driver.
+	Err error
+}
+
+// Error implements error interface.
+func (e *DriverError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the original error returned by the SQL driver.
+func (e *DriverError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the original error returned by the SQL driver.
+func (e *DriverError) Cause() error {
+	return e.Err
+}
+
+// IsUniqueViolation returns if given error is a unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	return hasErrorCode(err, CodeUniqueViolation)
+}
+
+// IsForeignKeyViolation returns if given error is a foreign key constraint violation.
+func IsForeignKeyViolation(err error) bool {
+	return hasErrorCode(err, CodeForeignKeyViolation)
+}
+
+// IsNotNullViolation returns if given error is a not-null constraint violation.
+func IsNotNullViolation(err error) bool {
+	return hasErrorCode(err, CodeNotNullViolation)
+}
+
+// IsCheckViolation returns if given error is a check constraint violation.
+func IsCheckViolation(err error) bool {
+	return hasErrorCode(err, CodeCheckViolation)
+}
+
+// IsSerializationFailure returns if given error is a serialization failure of a concurrent transaction.
+// The transaction should be retried.
+func IsSerializationFailure(err error) bool {
+	return hasErrorCode(err, CodeSerializationFailure)
+}
+
+// IsQueryCanceled returns if given error is a query canceled by the server, for example, after a statement
+// timeout.
+func IsQueryCanceled(err error) bool {
+	return hasErrorCode(err, CodeQueryCanceled)
+}
+
+// hasErrorCode returns if given error has been reported by the database server with given SQLSTATE code.
+func hasErrorCode(err error, code string) bool {
+	driverErr, ok := AsDriverError(err)
+	return ok && driverErr.Code == code
+}
+
+// withSchemaField maps the violated column of given error to a field of schema, if the error has been reported
+// on its table.
+// Otherwise, the error is returned untouched.
+func withSchemaField(err error, schema *Schema) error {
+	driverErr, ok := AsDriverError(err)
+	if !ok || driverErr.Column == "" || driverErr.Table != schema.TableName() {
+		return err
+	}
+
+	field, ok := schema.fields[driverErr.Column]
+	if !ok {
+		return err
+	}
+
+	mapped := *driverErr
+	mapped.Field = field.FieldName()
+
+	return errors.Wrap(&mapped, "makroud: cannot execute query")
+}
+
+// AsDriverError returns the error reported by the database server, if any, in given error chain.
+// It supports lib/pq errors and any error exposing its code with a SQLState method, such as pgx errors.
+func AsDriverError(err error) (*DriverError, bool) {
+	for err != nil {
+		switch e := err.(type) {
+		case *DriverError:
+			return e, true
+		case *pq.Error:
+			return newDriverErrorFromPQ(e), true
+		case pq.Error:
+			return newDriverErrorFromPQ(&e), true
+		case interface{ SQLState() string }:
+			return newDriverErrorFromSQLState(err, e.SQLState()), true
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil, false
+}
+
+// newDriverErrorFromPQ returns a DriverError from given lib/pq error.
+func newDriverErrorFromPQ(err *pq.Error) *DriverError {
+	return withDetailColumn(&DriverError{
+		Code:       string(err.Code),
+		Message:    err.Message,
+		Detail:     err.Detail,
+		Schema:     err.Schema,
+		Table:      err.Table,
+		Column:     err.Column,
+		Constraint: err.Constraint,
+		Err:        err,
+	})
+}
+
+// newDriverErrorFromSQLState returns a DriverError from given error with a SQLSTATE code.
+// Optional details are retrieved using the field names of lib/pq or pgx errors.
+func newDriverErrorFromSQLState(err error, code string) *DriverError {
+	value := reflect.Indirect(reflect.ValueOf(err))
+
+	field := func(names ...string) string {
+		if value.Kind() != reflect.Struct {
+			return ""
+		}
+		for _, name := range names {
+			field := value.FieldByName(name)
+			if field.IsValid() && field.Kind() == reflect.String {
+				return field.String()
+			}
+		}
+		return ""
+	}
+
+	return withDetailColumn(&DriverError{
+		Code:       code,
+		Message:    field("Message"),
+		Detail:     field("Detail"),
+		Schema:     field("SchemaName", "Schema"),
+		Table:      field("TableName", "Table"),
+		Column:     field("ColumnName", "Column"),
+		Constraint: field("ConstraintName", "Constraint"),
+		Err:        err,
+	})
+}
+
+// detailKeyPattern matches the key reported in the detail of unique and foreign key violations, such as:
+// Key (email)=(judy@zootopia.com) already exists.
+var detailKeyPattern = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// withDetailColumn retrieves the violated column from the error detail if the server didn't report it, which
+// is the case for unique and foreign key violations.
+// Keys with multiple columns are ignored.
+func withDetailColumn(err *DriverError) *DriverError {
+	if err.Column != "" {
+		return err
+	}
+
+	match := detailKeyPattern.FindStringSubmatch(err.Detail)
+	if len(match) == 2 && !strings.Contains(match[1], ",") {
+		err.Column = strings.Trim(match[1], `"`)
+	}
+
+	return err
+}
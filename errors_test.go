@@ -0,0 +1,124 @@
+package makroud_test
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/makroud"
+)
+
+// pgError mimics the error type returned by github.com/jackc/pgconn.
+type pgError struct {
+	Code           string
+	Message        string
+	Detail         string
+	SchemaName     string
+	TableName      string
+	ColumnName     string
+	ConstraintName string
+}
+
+func (e *pgError) Error() string {
+	return "ERROR: " + e.Message + " (SQLSTATE " + e.Code + ")"
+}
+
+func (e *pgError) SQLState() string {
+	return e.Code
+}
+
+func TestAsDriverError(t *testing.T) {
+	is := require.New(t)
+
+	{
+		err := errors.Wrap(&pq.Error{
+			Code:       "23505",
+			Message:    "duplicate key value violates unique constraint \"ztp_owl_name_key\"",
+			Schema:     "public",
+			Table:      "ztp_owl",
+			Constraint: "ztp_owl_name_key",
+		}, "makroud: cannot execute query")
+
+		driverErr, ok := makroud.AsDriverError(err)
+		is.True(ok)
+		is.NotNil(driverErr)
+		is.Equal("23505", driverErr.Code)
+		is.Equal("public", driverErr.Schema)
+		is.Equal("ztp_owl", driverErr.Table)
+		is.Equal("ztp_owl_name_key", driverErr.Constraint)
+		is.Equal("pq: duplicate key value violates unique constraint \"ztp_owl_name_key\"", driverErr.Error())
+	}
+	{
+		err := errors.Wrap(&pgError{
+			Code:       "23502",
+			Message:    "null value in column \"name\" violates not-null constraint",
+			TableName:  "ztp_cat",
+			ColumnName: "name",
+		}, "makroud: cannot execute query")
+
+		driverErr, ok := makroud.AsDriverError(err)
+		is.True(ok)
+		is.NotNil(driverErr)
+		is.Equal("23502", driverErr.Code)
+		is.Equal("ztp_cat", driverErr.Table)
+		is.Equal("name", driverErr.Column)
+		is.Equal("", driverErr.Constraint)
+	}
+	{
+		driverErr, ok := makroud.AsDriverError(errors.New("tcp: read timeout on 10.0.3.11:7000"))
+		is.False(ok)
+		is.Nil(driverErr)
+
+		driverErr, ok = makroud.AsDriverError(nil)
+		is.False(ok)
+		is.Nil(driverErr)
+	}
+}
+
+func TestDriverError_Predicates(t *testing.T) {
+	is := require.New(t)
+
+	unique := errors.Wrap(&pq.Error{
+		Code:       makroud.CodeUniqueViolation,
+		Message:    "duplicate key value violates unique constraint \"ztp_owl_name_key\"",
+		Detail:     "Key (name)=(Blake) already exists.",
+		Table:      "ztp_owl",
+		Constraint: "ztp_owl_name_key",
+	}, "makroud: cannot execute query")
+
+	is.True(makroud.IsUniqueViolation(unique))
+	is.False(makroud.IsForeignKeyViolation(unique))
+	is.False(makroud.IsNotNullViolation(unique))
+	is.False(makroud.IsCheckViolation(unique))
+	is.False(makroud.IsSerializationFailure(unique))
+	is.False(makroud.IsQueryCanceled(unique))
+
+	driverErr, ok := makroud.AsDriverError(unique)
+	is.True(ok)
+	is.Equal("name", driverErr.Column)
+	is.Equal("ztp_owl_name_key", driverErr.Constraint)
+
+	composite := &pgError{
+		Code:   makroud.CodeForeignKeyViolation,
+		Detail: "Key (owl_id, group_id)=(1, 2) is not present in table \"ztp_owl\".",
+	}
+
+	is.True(makroud.IsForeignKeyViolation(composite))
+	driverErr, ok = makroud.AsDriverError(composite)
+	is.True(ok)
+	is.Empty(driverErr.Column)
+
+	is.True(makroud.IsNotNullViolation(&pq.Error{Code: makroud.CodeNotNullViolation}))
+	is.True(makroud.IsCheckViolation(&pq.Error{Code: makroud.CodeCheckViolation}))
+	is.True(makroud.IsSerializationFailure(&pgError{Code: makroud.CodeSerializationFailure}))
+	is.True(makroud.IsQueryCanceled(errors.WithStack(&pgError{Code: makroud.CodeQueryCanceled})))
+	is.False(makroud.IsQueryCanceled(errors.New("tcp: read timeout on 10.0.3.11:7000")))
+
+	// errors.Cause must still return the original driver error.
+	wrapped := errors.Wrap(&makroud.DriverError{Code: makroud.CodeUniqueViolation, Err: unique}, "makroud: cannot save")
+	is.True(makroud.IsUniqueViolation(wrapped))
+	_, ok = errors.Cause(wrapped).(*pq.Error)
+	is.True(ok)
+}
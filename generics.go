@@ -0,0 +1,80 @@
+package makroud
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/ulule/loukoum/v3"
+)
+
+// ModelPointer is a constraint for a pointer to a model T.
+type ModelPointer[T any] interface {
+	*T
+	Model
+}
+
+// Find retrieves the first instance of T matching given arguments as criteria.
+// This function accepts the same arguments as Select.
+// If no instance is found, an error matching IsErrNoRows is returned.
+func Find[T any, PT ModelPointer[T]](ctx context.Context, driver Driver, args ...interface{}) (*T, error) {
+	dest := new(T)
+
+	err := selectRow(ctx, driver, PT(dest), args)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// FindAll retrieves every instance of T matching given arguments as criteria.
+// This function accepts the same arguments as Select.
+func FindAll[T any, PT ModelPointer[T]](ctx context.Context, driver Driver, args ...interface{}) ([]T, error) {
+	dest := []T{}
+
+	err := selectRows(ctx, driver, &dest, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// Get retrieves the instance of T with given primary key.
+// If no instance is found, an error matching IsErrNoRows is returned.
+func Get[T any, PT ModelPointer[T]](ctx context.Context, driver Driver, id interface{}) (*T, error) {
+	schema, err := GetSchema(driver, PT(new(T)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", new(T))
+	}
+
+	return Find[T, PT](ctx, driver, loukoum.Condition(schema.PrimaryKeyPath()).Equal(id))
+}
+
+// Exists returns if an instance of T matches given arguments as criteria.
+// This function accepts the same arguments as Select.
+func Exists[T any, PT ModelPointer[T]](ctx context.Context, driver Driver, args ...interface{}) (bool, error) {
+	model := PT(new(T))
+
+	schema, err := GetSchema(driver, model)
+	if err != nil {
+		return false, errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", model)
+	}
+
+	query, _ := parseSelectArgs(loukoum.Select("1").From(model.TableName()), args)
+	query = query.Limit(1)
+	if schema.HasDeletedKey() {
+		query = query.Where(loukoum.Condition(schema.DeletedKeyPath()).IsNull(true))
+	}
+
+	found := 0
+	err = Exec(ctx, driver, query, &found)
+	if IsErrNoRows(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
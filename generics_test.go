@@ -0,0 +1,78 @@
+package makroud_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+func TestGenerics(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		humans := []Human{
+			{Name: "Ethel"},
+			{Name: "Marcela"},
+			{Name: "Juliette"},
+		}
+
+		for i := range humans {
+			err := makroud.Save(ctx, driver, &humans[i])
+			is.NoError(err)
+		}
+
+		err := makroud.Archive(ctx, driver, &humans[2])
+		is.NoError(err)
+
+		{
+			human, err := makroud.Find[Human](ctx, driver, loukoum.Condition("name").Equal("Marcela"))
+			is.NoError(err)
+			is.NotNil(human)
+			is.Equal(humans[1].ID, human.ID)
+
+			human, err = makroud.Find[Human](ctx, driver, loukoum.Condition("name").Equal("Juliette"))
+			is.Error(err)
+			is.True(makroud.IsErrNoRows(err))
+			is.Nil(human)
+		}
+		{
+			list, err := makroud.FindAll[Human](ctx, driver, loukoum.Order("id", loukoum.Desc))
+			is.NoError(err)
+			is.Len(list, 2)
+			is.Equal(humans[1].ID, list[0].ID)
+			is.Equal(humans[0].ID, list[1].ID)
+
+			list, err = makroud.FindAll[Human](ctx, driver, loukoum.Condition("name").Equal("Sarah"))
+			is.NoError(err)
+			is.Empty(list)
+		}
+		{
+			human, err := makroud.Get[Human](ctx, driver, humans[0].ID)
+			is.NoError(err)
+			is.NotNil(human)
+			is.Equal("Ethel", human.Name)
+
+			human, err = makroud.Get[Human](ctx, driver, humans[2].ID)
+			is.True(makroud.IsErrNoRows(err))
+			is.Nil(human)
+		}
+		{
+			exists, err := makroud.Exists[Human](ctx, driver, loukoum.Condition("name").Equal("Ethel"))
+			is.NoError(err)
+			is.True(exists)
+
+			exists, err = makroud.Exists[Human](ctx, driver, loukoum.Condition("name").Equal("Juliette"))
+			is.NoError(err)
+			is.False(exists)
+
+			exists, err = makroud.Exists[Human](ctx, driver)
+			is.NoError(err)
+			is.True(exists)
+		}
+	})
+}
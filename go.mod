@@ -1,9 +1,8 @@
 module github.com/ulule/makroud
 
-go 1.12
+go 1.18
 
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gofrs/uuid v3.4.0+incompatible
 	github.com/lib/pq v1.10.4
 	github.com/oklog/ulid v1.3.1
@@ -11,3 +10,9 @@ require (
 	github.com/stretchr/testify v1.7.0
 	github.com/ulule/loukoum/v3 v3.4.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
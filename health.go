@@ -0,0 +1,189 @@
+package makroud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Health statuses.
+const (
+	// HealthStatusUp is reported when a connection is healthy.
+	HealthStatusUp = "up"
+	// HealthStatusDown is reported when a connection is unhealthy.
+	HealthStatusDown = "down"
+)
+
+// HealthReport describes the health of every connection of a Selector.
+type HealthReport struct {
+	Status      string                      `json:"status"`
+	Connections map[string]ConnectionHealth `json:"connections"`
+}
+
+// ConnectionHealth describes the health of a connection.
+type ConnectionHealth struct {
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Stats    *PoolStats    `json:"stats,omitempty"`
+}
+
+// PoolStats describes the statistics of a connection pool.
+type PoolStats struct {
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration"`
+	MaxIdleClosed      int64         `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64         `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64         `json:"max_lifetime_closed"`
+}
+
+// Health verifies every connection of the selector, including the configured ones that are not opened yet,
+// and returns a report with their pool statistics.
+// Connections are opened and verified concurrently, using given context.
+func (selector *Selector) Health(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Status:      HealthStatusUp,
+		Connections: map[string]ConnectionHealth{},
+	}
+
+	aliases := selector.aliases()
+	healths := make([]ConnectionHealth, len(aliases))
+
+	wg := sync.WaitGroup{}
+	for i := range aliases {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			healths[i] = selector.getHealth(ctx, aliases[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range aliases {
+		if healths[i].Status != HealthStatusUp {
+			report.Status = HealthStatusDown
+		}
+		report.Connections[aliases[i]] = healths[i]
+	}
+
+	return report
+}
+
+// getHealth opens the connection with given alias, if required, and returns its health.
+func (selector *Selector) getHealth(ctx context.Context, alias string) ConnectionHealth {
+	start := time.Now()
+	driver, err := selector.usingContext(ctx, alias)
+	if err != nil {
+		return ConnectionHealth{
+			Status:   HealthStatusDown,
+			Error:    err.Error(),
+			Duration: time.Since(start),
+		}
+	}
+
+	return getConnectionHealth(ctx, driver)
+}
+
+// usingContext returns the driver with given alias, like Using, but gives up as soon as given context is done.
+// If the connection is being opened, it's kept by the selector once opened.
+func (selector *Selector) usingContext(ctx context.Context, alias string) (Driver, error) {
+	type result struct {
+		driver Driver
+		err    error
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		driver, err := selector.Using(alias)
+		results <- result{driver: driver, err: err}
+	}()
+
+	select {
+	case result := <-results:
+		return result.driver, result.err
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "makroud: cannot open connection alias '%s'", alias)
+	}
+}
+
+// getConnectionHealth verifies given connection and returns its health.
+func getConnectionHealth(ctx context.Context, driver Driver) ConnectionHealth {
+	health := ConnectionHealth{
+		Status: HealthStatusUp,
+	}
+
+	start := time.Now()
+	err := driver.PingContext(ctx)
+	health.Duration = time.Since(start)
+
+	stats := driver.Stats()
+	health.Stats = &PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+
+	if err != nil {
+		health.Status = HealthStatusDown
+		health.Error = err.Error()
+	}
+
+	return health
+}
+
+// aliases returns the sorted list of opened and configured connection aliases.
+func (selector *Selector) aliases() []string {
+	selector.mutex.RLock()
+	defer selector.mutex.RUnlock()
+
+	set := map[string]struct{}{}
+	for alias := range selector.connections {
+		set[alias] = struct{}{}
+	}
+	for alias := range selector.configurations {
+		set[strings.ToLower(alias)] = struct{}{}
+	}
+
+	aliases := make([]string, 0, len(set))
+	for alias := range set {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	return aliases
+}
+
+// NewHealthHandler returns a http.Handler reporting the health of every connection of given selector,
+// in JSON format.
+// It responds with a 200 status code if every connection is healthy, and a 503 status code otherwise.
+func NewHealthHandler(selector *Selector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := selector.Health(r.Context())
+
+		status := http.StatusOK
+		if report.Status != HealthStatusUp {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
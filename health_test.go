@@ -0,0 +1,111 @@
+package makroud_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/makroud"
+)
+
+func TestHealthHandler(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		is := require.New(t)
+
+		unreachable := ClientOptions(makroud.Host("10.0.0.1"), makroud.ConnectTimeout(1))
+		selector, err := makroud.NewSelector(map[string]*makroud.ClientOptions{
+			makroud.MasterSelector: ClientOptions(),
+		})
+		is.NoError(err)
+		defer func() {
+			is.NoError(selector.Close())
+		}()
+
+		_, err = selector.Using(makroud.MasterSelector)
+		is.NoError(err)
+
+		handler := makroud.NewHealthHandler(selector)
+
+		request := httptest.NewRequest(http.MethodGet, "/health", nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		is.Equal(http.StatusOK, recorder.Code)
+		is.Equal("application/json", recorder.Header().Get("Content-Type"))
+
+		report := makroud.HealthReport{}
+		err = json.Unmarshal(recorder.Body.Bytes(), &report)
+		is.NoError(err)
+		is.Equal(makroud.HealthStatusUp, report.Status)
+		is.Len(report.Connections, 1)
+		is.Equal(makroud.HealthStatusUp, report.Connections[makroud.MasterSelector].Status)
+		is.NotNil(report.Connections[makroud.MasterSelector].Stats)
+		is.True(report.Connections[makroud.MasterSelector].Stats.OpenConnections > 0)
+
+		selector, err = makroud.NewSelector(map[string]*makroud.ClientOptions{
+			makroud.MasterSelector: ClientOptions(),
+			makroud.SlaveSelector:  unreachable,
+		})
+		is.NoError(err)
+		defer func() {
+			is.NoError(selector.Close())
+		}()
+
+		_, err = selector.Using(makroud.MasterSelector)
+		is.NoError(err)
+
+		report = selector.Health(context.Background())
+		is.Equal(makroud.HealthStatusDown, report.Status)
+		is.Len(report.Connections, 2)
+		is.Equal(makroud.HealthStatusUp, report.Connections[makroud.MasterSelector].Status)
+		is.Equal(makroud.HealthStatusDown, report.Connections[makroud.SlaveSelector].Status)
+		is.NotEmpty(report.Connections[makroud.SlaveSelector].Error)
+		is.Nil(report.Connections[makroud.SlaveSelector].Stats)
+
+		selector, err = makroud.NewSelector(map[string]*makroud.ClientOptions{
+			makroud.MasterSelector: ClientOptions(),
+			makroud.SlaveSelector:  ClientOptions(),
+		})
+		is.NoError(err)
+		defer func() {
+			is.NoError(selector.Close())
+		}()
+
+		report = selector.Health(context.Background())
+		is.Equal(makroud.HealthStatusUp, report.Status)
+		is.Len(report.Connections, 2)
+		is.Equal(makroud.HealthStatusUp, report.Connections[makroud.MasterSelector].Status)
+		is.Equal(makroud.HealthStatusUp, report.Connections[makroud.SlaveSelector].Status)
+		is.True(report.Connections[makroud.SlaveSelector].Stats.OpenConnections > 0)
+
+		slave, err := makroud.NewWithOptions(ClientOptions())
+		is.NoError(err)
+		is.NoError(slave.Close())
+
+		selector, err = makroud.NewSelectorWithDrivers(map[string]makroud.Driver{
+			makroud.MasterSelector: driver,
+			makroud.SlaveSelector:  slave,
+		})
+		is.NoError(err)
+
+		handler = makroud.NewHealthHandler(selector)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		is.Equal(http.StatusServiceUnavailable, recorder.Code)
+
+		report = makroud.HealthReport{}
+		err = json.Unmarshal(recorder.Body.Bytes(), &report)
+		is.NoError(err)
+		is.Equal(makroud.HealthStatusDown, report.Status)
+		is.Len(report.Connections, 2)
+		is.Equal(makroud.HealthStatusUp, report.Connections[makroud.MasterSelector].Status)
+		is.Equal(makroud.HealthStatusDown, report.Connections[makroud.SlaveSelector].Status)
+		is.NotEmpty(report.Connections[makroud.SlaveSelector].Error)
+	})
+}
@@ -16,18 +16,31 @@ import (
 // Exec will execute given query from a Loukoum builder.
 // If an object is given, it will mutate it to match the row values.
 func Exec(ctx context.Context, driver Driver, stmt builder.Builder, dest ...interface{}) error {
-	if driver.HasLogger() {
+	dialect := getDialect(driver)
+
+	if isTraced(ctx, driver) {
 		start := time.Now()
 		query := NewQuery(stmt)
+		query.Query = dialect.Rebind(query.Query)
 
 		defer func() {
-			Log(ctx, driver, query, time.Since(start))
+			trace(ctx, driver, query, time.Since(start))
 		}()
 	}
 
 	query, args := stmt.Query()
+	query = dialect.Rebind(query)
+
+	handler := func() error {
+		return exec(ctx, driver, query, args, dest...)
+	}
 
-	err := exec(ctx, driver, query, args, dest...)
+	var err error
+	if isRetrySafe(ctx, stmt) {
+		err = retry(ctx, driver, handler, dest...)
+	} else {
+		err = handler()
+	}
 	if err != nil {
 		return errors.Wrap(err, "makroud: cannot execute query")
 	}
@@ -38,16 +51,25 @@ func Exec(ctx context.Context, driver Driver, stmt builder.Builder, dest ...inte
 // RawExec will execute given query.
 // If an object is given, it will mutate it to match the row values.
 func RawExec(ctx context.Context, driver Driver, query string, dest ...interface{}) error {
-	if driver.HasLogger() {
+	if isTraced(ctx, driver) {
 		start := time.Now()
 		query := NewRawQuery(query)
 
 		defer func() {
-			Log(ctx, driver, query, time.Since(start))
+			trace(ctx, driver, query, time.Since(start))
 		}()
 	}
 
-	err := exec(ctx, driver, query, nil, dest...)
+	handler := func() error {
+		return exec(ctx, driver, query, nil, dest...)
+	}
+
+	var err error
+	if isRetrySafe(ctx, nil) {
+		err = retry(ctx, driver, handler, dest...)
+	} else {
+		err = handler()
+	}
 	if err != nil {
 		return errors.Wrap(err, "makroud: cannot execute query")
 	}
@@ -58,7 +80,7 @@ func RawExec(ctx context.Context, driver Driver, query string, dest ...interface
 // RawExecArgs will execute given query with given arguments.
 // If an object is given, it will mutate it to match the row values.
 func RawExecArgs(ctx context.Context, driver Driver, query string, args []interface{}, dest ...interface{}) error {
-	if driver.HasLogger() {
+	if isTraced(ctx, driver) {
 		start := time.Now()
 		query := Query{
 			Raw:   query,
@@ -67,11 +89,20 @@ func RawExecArgs(ctx context.Context, driver Driver, query string, args []interf
 		}
 
 		defer func() {
-			Log(ctx, driver, query, time.Since(start))
+			trace(ctx, driver, query, time.Since(start))
 		}()
 	}
 
-	err := exec(ctx, driver, query, args, dest...)
+	handler := func() error {
+		return exec(ctx, driver, query, args, dest...)
+	}
+
+	var err error
+	if isRetrySafe(ctx, nil) {
+		err = retry(ctx, driver, handler, dest...)
+	} else {
+		err = handler()
+	}
 	if err != nil {
 		return errors.Wrap(err, "makroud: cannot execute query")
 	}
@@ -109,6 +140,54 @@ func FloatCount(ctx context.Context, driver Driver, stmt builder.Builder) (float
 	return count, nil
 }
 
+// queryRows executes given query and calls handler with its rows, using the same tracing and retry mechanisms
+// as Exec.
+func queryRows(ctx context.Context, driver Driver, stmt builder.Builder, dest interface{},
+	flags map[string]string, handler func(rows Rows) error) error {
+
+	dialect := getDialect(driver)
+
+	if isTraced(ctx, driver) {
+		start := time.Now()
+		query := NewQuery(stmt)
+		query.Query = dialect.Rebind(query.Query)
+
+		defer func() {
+			trace(ctx, driver, query, time.Since(start))
+		}()
+	}
+
+	query, args := stmt.Query()
+	query = dialect.Rebind(query)
+
+	callback := func() error {
+		rows, err := driver.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer close(driver, rows, flags)
+
+		err = handler(rows)
+		if err != nil {
+			return err
+		}
+
+		return rows.Err()
+	}
+
+	var err error
+	if isRetrySafe(ctx, stmt) {
+		err = retry(ctx, driver, callback, dest)
+	} else {
+		err = callback()
+	}
+	if err != nil {
+		return errors.Wrap(err, "makroud: cannot execute query")
+	}
+
+	return nil
+}
+
 // IsErrNoRows returns if given error is a "no rows" error.
 func IsErrNoRows(err error) bool {
 	if err == nil {
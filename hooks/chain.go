@@ -0,0 +1,156 @@
+package hooks
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// Hooks defines a subscriber on driver events. Every function is optional.
+//
+// When several subscribers are registered on a Connector, Before functions are executed in registration order,
+// each one receiving the context returned by the previous one. After functions are executed in reverse order,
+// so a subscriber wraps every subscriber registered after it, like a middleware.
+type Hooks struct {
+	BeforeConnect func(ctx context.Context) context.Context
+	AfterConnect  func(ctx context.Context, conn driver.Conn, err error)
+
+	BeforeExec func(ctx context.Context, query string, args []driver.NamedValue) context.Context
+	AfterExec  func(ctx context.Context, result driver.Result, err error)
+
+	BeforeQuery func(ctx context.Context, query string, args []driver.NamedValue) context.Context
+	AfterQuery  func(ctx context.Context, rows driver.Rows, err error)
+
+	BeforePrepare func(ctx context.Context, query string) context.Context
+	AfterPrepare  func(ctx context.Context, stmt driver.Stmt, err error)
+
+	BeforeBegin func(ctx context.Context, opts driver.TxOptions) context.Context
+	AfterBegin  func(ctx context.Context, tx driver.Tx, err error)
+
+	BeforeCommit func(ctx context.Context) context.Context
+	AfterCommit  func(ctx context.Context, err error)
+
+	BeforeRollback func(ctx context.Context) context.Context
+	AfterRollback  func(ctx context.Context, err error)
+}
+
+// chain is an ordered list of subscribers.
+type chain []Hooks
+
+func (c chain) beforeConnect(ctx context.Context) context.Context {
+	for i := range c {
+		if c[i].BeforeConnect != nil {
+			ctx = c[i].BeforeConnect(ctx)
+		}
+	}
+	return ctx
+}
+
+func (c chain) afterConnect(ctx context.Context, conn driver.Conn, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].AfterConnect != nil {
+			c[i].AfterConnect(ctx, conn, err)
+		}
+	}
+}
+
+func (c chain) beforeExec(ctx context.Context, query string, args []driver.NamedValue) context.Context {
+	for i := range c {
+		if c[i].BeforeExec != nil {
+			ctx = c[i].BeforeExec(ctx, query, args)
+		}
+	}
+	return ctx
+}
+
+func (c chain) afterExec(ctx context.Context, result driver.Result, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].AfterExec != nil {
+			c[i].AfterExec(ctx, result, err)
+		}
+	}
+}
+
+func (c chain) beforeQuery(ctx context.Context, query string, args []driver.NamedValue) context.Context {
+	for i := range c {
+		if c[i].BeforeQuery != nil {
+			ctx = c[i].BeforeQuery(ctx, query, args)
+		}
+	}
+	return ctx
+}
+
+func (c chain) afterQuery(ctx context.Context, rows driver.Rows, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].AfterQuery != nil {
+			c[i].AfterQuery(ctx, rows, err)
+		}
+	}
+}
+
+func (c chain) beforePrepare(ctx context.Context, query string) context.Context {
+	for i := range c {
+		if c[i].BeforePrepare != nil {
+			ctx = c[i].BeforePrepare(ctx, query)
+		}
+	}
+	return ctx
+}
+
+func (c chain) afterPrepare(ctx context.Context, stmt driver.Stmt, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].AfterPrepare != nil {
+			c[i].AfterPrepare(ctx, stmt, err)
+		}
+	}
+}
+
+func (c chain) beforeBegin(ctx context.Context, opts driver.TxOptions) context.Context {
+	for i := range c {
+		if c[i].BeforeBegin != nil {
+			ctx = c[i].BeforeBegin(ctx, opts)
+		}
+	}
+	return ctx
+}
+
+func (c chain) afterBegin(ctx context.Context, tx driver.Tx, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].AfterBegin != nil {
+			c[i].AfterBegin(ctx, tx, err)
+		}
+	}
+}
+
+func (c chain) beforeCommit(ctx context.Context) context.Context {
+	for i := range c {
+		if c[i].BeforeCommit != nil {
+			ctx = c[i].BeforeCommit(ctx)
+		}
+	}
+	return ctx
+}
+
+func (c chain) afterCommit(ctx context.Context, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].AfterCommit != nil {
+			c[i].AfterCommit(ctx, err)
+		}
+	}
+}
+
+func (c chain) beforeRollback(ctx context.Context) context.Context {
+	for i := range c {
+		if c[i].BeforeRollback != nil {
+			ctx = c[i].BeforeRollback(ctx)
+		}
+	}
+	return ctx
+}
+
+func (c chain) afterRollback(ctx context.Context, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].AfterRollback != nil {
+			c[i].AfterRollback(ctx, err)
+		}
+	}
+}
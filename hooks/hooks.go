@@ -5,12 +5,20 @@ package hooks
 import (
 	"context"
 	"database/sql/driver"
+	"errors"
 )
 
-// Wrap returns a new Connector wrapping c.
-func Wrap(c driver.Connector) *Connector { return &Connector{wrapped: c} }
+// Wrap returns a new Connector wrapping c, with given subscribers registered in order.
+func Wrap(c driver.Connector, subscribers ...Hooks) *Connector {
+	return &Connector{
+		wrapped:     c,
+		subscribers: subscribers,
+	}
+}
 
 // A Connector wraps an existing connector.
+//
+// Its functions are the first subscriber, followed by the ones registered with Wrap or Use.
 type Connector struct {
 	BeforeConnect func(ctx context.Context) context.Context
 	AfterConnect  func(ctx context.Context, conn driver.Conn, err error)
@@ -21,6 +29,9 @@ type Connector struct {
 	BeforeQuery func(ctx context.Context, query string, args []driver.NamedValue) context.Context
 	AfterQuery  func(ctx context.Context, rows driver.Rows, err error)
 
+	BeforePrepare func(ctx context.Context, query string) context.Context
+	AfterPrepare  func(ctx context.Context, stmt driver.Stmt, err error)
+
 	BeforeBegin func(ctx context.Context, opts driver.TxOptions) context.Context
 	AfterBegin  func(ctx context.Context, tx driver.Tx, err error)
 
@@ -30,31 +41,55 @@ type Connector struct {
 	BeforeRollback func(ctx context.Context) context.Context
 	AfterRollback  func(ctx context.Context, err error)
 
-	wrapped driver.Connector
+	subscribers []Hooks
+	wrapped     driver.Connector
 }
 
-// Connect implements database/sql/driver.Connector.
-func (connector *Connector) Connect(ctx context.Context) (driver.Conn, error) {
-	if connector.BeforeConnect != nil {
-		ctx = connector.BeforeConnect(ctx)
-	}
-	c, err := connector.wrapped.Connect(ctx)
-	if connector.AfterConnect != nil {
-		connector.AfterConnect(ctx, c, err)
-	}
-	return &conn{
-		wrapped:        c,
+// Use registers given subscribers, after the existing ones.
+// It must be called before the connector is used by a sql.DB.
+func (connector *Connector) Use(subscribers ...Hooks) *Connector {
+	connector.subscribers = append(connector.subscribers, subscribers...)
+	return connector
+}
+
+// chain returns the ordered list of subscribers.
+func (connector *Connector) chain() chain {
+	list := make(chain, 0, len(connector.subscribers)+1)
+	list = append(list, Hooks{
+		BeforeConnect:  connector.BeforeConnect,
+		AfterConnect:   connector.AfterConnect,
 		BeforeExec:     connector.BeforeExec,
 		AfterExec:      connector.AfterExec,
 		BeforeQuery:    connector.BeforeQuery,
 		AfterQuery:     connector.AfterQuery,
+		BeforePrepare:  connector.BeforePrepare,
+		AfterPrepare:   connector.AfterPrepare,
 		BeforeBegin:    connector.BeforeBegin,
 		AfterBegin:     connector.AfterBegin,
 		BeforeCommit:   connector.BeforeCommit,
 		AfterCommit:    connector.AfterCommit,
 		BeforeRollback: connector.BeforeRollback,
 		AfterRollback:  connector.AfterRollback,
-	}, err
+	})
+	list = append(list, connector.subscribers...)
+	return list
+}
+
+// Connect implements database/sql/driver.Connector.
+func (connector *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	hooks := connector.chain()
+
+	ctx = hooks.beforeConnect(ctx)
+	c, err := connector.wrapped.Connect(ctx)
+	hooks.afterConnect(ctx, c, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{
+		wrapped: c,
+		hooks:   hooks,
+	}, nil
 }
 
 // Driver implements database/sql/driver.Connector.
@@ -62,17 +97,7 @@ func (connector *Connector) Driver() driver.Driver { return connector.wrapped.Dr
 
 type conn struct {
 	wrapped driver.Conn
-
-	BeforeExec     func(ctx context.Context, query string, args []driver.NamedValue) context.Context
-	AfterExec      func(ctx context.Context, result driver.Result, err error)
-	BeforeQuery    func(ctx context.Context, query string, args []driver.NamedValue) context.Context
-	AfterQuery     func(ctx context.Context, rows driver.Rows, err error)
-	BeforeBegin    func(ctx context.Context, opts driver.TxOptions) context.Context
-	AfterBegin     func(ctx context.Context, tx driver.Tx, err error)
-	BeforeCommit   func(ctx context.Context) context.Context
-	AfterCommit    func(ctx context.Context, err error)
-	BeforeRollback func(ctx context.Context) context.Context
-	AfterRollback  func(ctx context.Context, err error)
+	hooks   chain
 }
 
 func (c *conn) Begin() (driver.Tx, error) {
@@ -85,85 +110,256 @@ func (c *conn) Close() error {
 }
 
 func (c *conn) Prepare(query string) (driver.Stmt, error) {
-	return c.wrapped.Prepare(query)
+	return c.PrepareContext(context.Background(), query)
 }
 
 var (
-	_ driver.ExecerContext  = &conn{}
-	_ driver.QueryerContext = &conn{}
-	_ driver.ConnBeginTx    = &conn{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.Pinger             = &conn{}
+	_ driver.SessionResetter    = &conn{}
+	_ driver.Validator          = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
 )
 
 func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
-	if c.BeforeExec != nil {
-		ctx = c.BeforeExec(ctx, query, args)
-	}
-	result, err := c.wrapped.(driver.ExecerContext).ExecContext(ctx, query, args)
-	if c.AfterExec != nil {
-		c.AfterExec(ctx, result, err)
+	execer, ok := c.wrapped.(driver.ExecerContext)
+	if !ok {
+		// Let database/sql fallback on a prepared statement.
+		return nil, driver.ErrSkip
 	}
+	ctx = c.hooks.beforeExec(ctx, query, args)
+	result, err := execer.ExecContext(ctx, query, args)
+	c.hooks.afterExec(ctx, result, err)
 	return result, err
 }
 
 func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
-	if c.BeforeQuery != nil {
-		ctx = c.BeforeQuery(ctx, query, args)
-	}
-	rows, err := c.wrapped.(driver.QueryerContext).QueryContext(ctx, query, args)
-	if c.AfterQuery != nil {
-		c.AfterQuery(ctx, rows, err)
+	queryer, ok := c.wrapped.(driver.QueryerContext)
+	if !ok {
+		// Let database/sql fallback on a prepared statement.
+		return nil, driver.ErrSkip
 	}
+	ctx = c.hooks.beforeQuery(ctx, query, args)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.hooks.afterQuery(ctx, rows, err)
 	return rows, err
 }
 
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	ctx = c.hooks.beforePrepare(ctx, query)
+
+	var (
+		s   driver.Stmt
+		err error
+	)
+	preparer, ok := c.wrapped.(driver.ConnPrepareContext)
+	if ok {
+		s, err = preparer.PrepareContext(ctx, query)
+	} else {
+		s, err = c.wrapped.Prepare(query)
+	}
+
+	c.hooks.afterPrepare(ctx, s, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stmt{
+		wrapped: s,
+		conn:    c.wrapped,
+		query:   query,
+		hooks:   c.hooks,
+	}, nil
+}
+
 func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
-	if c.BeforeBegin != nil {
-		ctx = c.BeforeBegin(ctx, opts)
+	ctx = c.hooks.beforeBegin(ctx, opts)
+
+	var (
+		t   driver.Tx
+		err error
+	)
+	beginner, ok := c.wrapped.(driver.ConnBeginTx)
+	if ok {
+		t, err = beginner.BeginTx(ctx, opts)
+	} else {
+		// nolint:staticcheck
+		t, err = c.wrapped.Begin()
 	}
-	t, err := c.wrapped.(driver.ConnBeginTx).BeginTx(ctx, opts)
-	if c.AfterBegin != nil {
-		c.AfterBegin(ctx, t, err)
+
+	c.hooks.afterBegin(ctx, t, err)
+	if err != nil {
+		return nil, err
 	}
+
 	return &tx{
-		wrapped:        t,
-		ctx:            ctx,
-		BeforeCommit:   c.BeforeCommit,
-		AfterCommit:    c.AfterCommit,
-		BeforeRollback: c.BeforeRollback,
-		AfterRollback:  c.AfterRollback,
-	}, err
+		wrapped: t,
+		ctx:     ctx,
+		hooks:   c.hooks,
+	}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	pinger, ok := c.wrapped.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	return pinger.Ping(ctx)
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	resetter, ok := c.wrapped.(driver.SessionResetter)
+	if !ok {
+		return nil
+	}
+	return resetter.ResetSession(ctx)
+}
+
+func (c *conn) IsValid() bool {
+	validator, ok := c.wrapped.(driver.Validator)
+	if !ok {
+		return true
+	}
+	return validator.IsValid()
+}
+
+func (c *conn) CheckNamedValue(value *driver.NamedValue) error {
+	checker, ok := c.wrapped.(driver.NamedValueChecker)
+	if !ok {
+		// Let database/sql use its default converter.
+		return driver.ErrSkip
+	}
+	return checker.CheckNamedValue(value)
 }
 
 type tx struct {
 	wrapped driver.Tx
 	ctx     context.Context
-
-	BeforeCommit   func(ctx context.Context) context.Context
-	AfterCommit    func(ctx context.Context, err error)
-	BeforeRollback func(ctx context.Context) context.Context
-	AfterRollback  func(ctx context.Context, err error)
+	hooks   chain
 }
 
 func (tx *tx) Commit() error {
-	ctx := tx.ctx
-	if tx.BeforeCommit != nil {
-		ctx = tx.BeforeCommit(ctx)
-	}
+	ctx := tx.hooks.beforeCommit(tx.ctx)
 	err := tx.wrapped.Commit()
-	if tx.AfterCommit != nil {
-		tx.AfterCommit(ctx, err)
-	}
+	tx.hooks.afterCommit(ctx, err)
 	return err
 }
 
 func (tx *tx) Rollback() error {
-	ctx := tx.ctx
-	if tx.BeforeRollback != nil {
-		ctx = tx.BeforeRollback(ctx)
-	}
+	ctx := tx.hooks.beforeRollback(tx.ctx)
 	err := tx.wrapped.Rollback()
-	if tx.AfterRollback != nil {
-		tx.AfterRollback(ctx, err)
-	}
+	tx.hooks.afterRollback(ctx, err)
 	return err
 }
+
+type stmt struct {
+	wrapped driver.Stmt
+	conn    driver.Conn
+	query   string
+	hooks   chain
+}
+
+var (
+	_ driver.StmtExecContext   = &stmt{}
+	_ driver.StmtQueryContext  = &stmt{}
+	_ driver.NamedValueChecker = &stmt{}
+)
+
+func (s *stmt) Close() error {
+	return s.wrapped.Close()
+}
+
+func (s *stmt) NumInput() int {
+	return s.wrapped.NumInput()
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), toNamedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), toNamedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx = s.hooks.beforeExec(ctx, s.query, args)
+
+	var (
+		result driver.Result
+		err    error
+	)
+	execer, ok := s.wrapped.(driver.StmtExecContext)
+	if ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		values, thr := toValues(args)
+		if thr != nil {
+			return nil, thr
+		}
+		// nolint:staticcheck
+		result, err = s.wrapped.Exec(values)
+	}
+
+	s.hooks.afterExec(ctx, result, err)
+	return result, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx = s.hooks.beforeQuery(ctx, s.query, args)
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+	queryer, ok := s.wrapped.(driver.StmtQueryContext)
+	if ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		values, thr := toValues(args)
+		if thr != nil {
+			return nil, thr
+		}
+		// nolint:staticcheck
+		rows, err = s.wrapped.Query(values)
+	}
+
+	s.hooks.afterQuery(ctx, rows, err)
+	return rows, err
+}
+
+func (s *stmt) CheckNamedValue(value *driver.NamedValue) error {
+	checker, ok := s.wrapped.(driver.NamedValueChecker)
+	if !ok {
+		checker, ok = s.conn.(driver.NamedValueChecker)
+	}
+	if !ok {
+		// Let database/sql use its default converter.
+		return driver.ErrSkip
+	}
+	return checker.CheckNamedValue(value)
+}
+
+func toNamedValues(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i := range args {
+		values[i] = driver.NamedValue{
+			Ordinal: i + 1,
+			Value:   args[i],
+		}
+	}
+	return values
+}
+
+func toValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i := range args {
+		if args[i].Name != "" {
+			return nil, errors.New("hooks: driver does not support the use of named parameters")
+		}
+		values[i] = args[i].Value
+	}
+	return values, nil
+}
@@ -0,0 +1,322 @@
+package hooks_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/makroud/hooks"
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConnector struct {
+	mutex   sync.Mutex
+	conns   []*fakeConn
+	invalid bool
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	conn := &fakeConn{connector: c}
+	c.conns = append(c.conns, conn)
+	return conn, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+	pings     int
+	resets    int
+	checks    int
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	c.pings++
+	return nil
+}
+
+func (c *fakeConn) ResetSession(ctx context.Context) error {
+	c.resets++
+	return nil
+}
+
+func (c *fakeConn) IsValid() bool {
+	return c.connector == nil || !c.connector.invalid
+}
+
+func (c *fakeConn) CheckNamedValue(value *driver.NamedValue) error {
+	c.checks++
+	return driver.ErrSkip
+}
+
+type fakeStmt struct{}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(int64(len(args))), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(42)
+	return nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+func TestConnector_PreparedStatement(t *testing.T) {
+	is := require.New(t)
+	ctx := context.Background()
+
+	prepared := []string{}
+	executed := []string{}
+	queried := []string{}
+
+	connector := hooks.Wrap(&fakeConnector{})
+	connector.BeforePrepare = func(ctx context.Context, query string) context.Context {
+		prepared = append(prepared, query)
+		return ctx
+	}
+	connector.BeforeExec = func(ctx context.Context, query string, args []driver.NamedValue) context.Context {
+		executed = append(executed, query)
+		return ctx
+	}
+	connector.BeforeQuery = func(ctx context.Context, query string, args []driver.NamedValue) context.Context {
+		queried = append(queried, query)
+		return ctx
+	}
+
+	db := sql.OpenDB(connector)
+	defer func() {
+		is.NoError(db.Close())
+	}()
+
+	stmt, err := db.PrepareContext(ctx, "UPDATE foo SET bar = $1")
+	is.NoError(err)
+
+	result, err := stmt.ExecContext(ctx, "baz")
+	is.NoError(err)
+	affected, err := result.RowsAffected()
+	is.NoError(err)
+	is.Equal(int64(1), affected)
+	is.NoError(stmt.Close())
+
+	stmt, err = db.PrepareContext(ctx, "SELECT value FROM foo")
+	is.NoError(err)
+
+	value := int64(0)
+	err = stmt.QueryRowContext(ctx).Scan(&value)
+	is.NoError(err)
+	is.Equal(int64(42), value)
+	is.NoError(stmt.Close())
+
+	is.Equal([]string{"UPDATE foo SET bar = $1", "SELECT value FROM foo"}, prepared)
+	is.Equal([]string{"UPDATE foo SET bar = $1"}, executed)
+	is.Equal([]string{"SELECT value FROM foo"}, queried)
+}
+
+func TestConnector_QueryFallback(t *testing.T) {
+	is := require.New(t)
+	ctx := context.Background()
+
+	queried := []string{}
+
+	connector := hooks.Wrap(&fakeConnector{})
+	connector.BeforeQuery = func(ctx context.Context, query string, args []driver.NamedValue) context.Context {
+		queried = append(queried, query)
+		return ctx
+	}
+
+	db := sql.OpenDB(connector)
+	defer func() {
+		is.NoError(db.Close())
+	}()
+
+	// The fake connection doesn't implement driver.QueryerContext: a prepared statement must be used instead.
+	value := int64(0)
+	err := db.QueryRowContext(ctx, "SELECT value FROM foo WHERE id = $1", 1).Scan(&value)
+	is.NoError(err)
+	is.Equal(int64(42), value)
+	is.Equal([]string{"SELECT value FROM foo WHERE id = $1"}, queried)
+}
+
+func TestConnector_OptionalInterfaces(t *testing.T) {
+	is := require.New(t)
+	ctx := context.Background()
+
+	fake := &fakeConnector{}
+	db := sql.OpenDB(hooks.Wrap(fake))
+	defer func() {
+		is.NoError(db.Close())
+	}()
+	db.SetMaxOpenConns(1)
+
+	err := db.PingContext(ctx)
+	is.NoError(err)
+
+	_, err = db.ExecContext(ctx, "UPDATE foo SET bar = $1", "baz")
+	is.NoError(err)
+
+	is.Len(fake.conns, 1)
+	conn := fake.conns[0]
+	is.Equal(1, conn.pings)
+	is.True(conn.resets > 0)
+	is.True(conn.checks > 0)
+
+	// An invalid connection must be discarded by the pool once released.
+	fake.invalid = true
+
+	_, err = db.ExecContext(ctx, "UPDATE foo SET bar = $1", "qux")
+	is.NoError(err)
+	_, err = db.ExecContext(ctx, "UPDATE foo SET bar = $1", "quux")
+	is.NoError(err)
+	is.Len(fake.conns, 2)
+}
+
+type chainKey struct{}
+
+func TestConnector_Chain(t *testing.T) {
+	is := require.New(t)
+	ctx := context.Background()
+
+	events := []string{}
+	subscriber := func(name string) hooks.Hooks {
+		return hooks.Hooks{
+			BeforeExec: func(ctx context.Context, query string, args []driver.NamedValue) context.Context {
+				path, _ := ctx.Value(chainKey{}).(string)
+				events = append(events, "before:"+name)
+				return context.WithValue(ctx, chainKey{}, path+"/"+name)
+			},
+			AfterExec: func(ctx context.Context, result driver.Result, err error) {
+				path, _ := ctx.Value(chainKey{}).(string)
+				events = append(events, "after:"+name+":"+path)
+			},
+		}
+	}
+
+	connector := hooks.Wrap(&fakeConnector{}, subscriber("logging"), subscriber("tracing"))
+	connector.Use(subscriber("metrics"))
+
+	// Functions defined on the connector are the first subscriber.
+	connector.BeforeExec = subscriber("connector").BeforeExec
+	connector.AfterExec = subscriber("connector").AfterExec
+
+	db := sql.OpenDB(connector)
+	defer func() {
+		is.NoError(db.Close())
+	}()
+
+	_, err := db.ExecContext(ctx, "UPDATE foo SET bar = $1", "baz")
+	is.NoError(err)
+
+	is.Equal([]string{
+		"before:connector",
+		"before:logging",
+		"before:tracing",
+		"before:metrics",
+		"after:metrics:/connector/logging/tracing/metrics",
+		"after:tracing:/connector/logging/tracing/metrics",
+		"after:logging:/connector/logging/tracing/metrics",
+		"after:connector:/connector/logging/tracing/metrics",
+	}, events)
+}
+
+func TestConnector_Transaction(t *testing.T) {
+	is := require.New(t)
+	ctx := context.Background()
+
+	events := []string{}
+	connector := hooks.Wrap(&fakeConnector{}, hooks.Hooks{
+		BeforeBegin: func(ctx context.Context, opts driver.TxOptions) context.Context {
+			events = append(events, "begin")
+			return ctx
+		},
+		AfterCommit: func(ctx context.Context, err error) {
+			events = append(events, "commit")
+		},
+		AfterRollback: func(ctx context.Context, err error) {
+			events = append(events, "rollback")
+		},
+	})
+
+	db := sql.OpenDB(connector)
+	defer func() {
+		is.NoError(db.Close())
+	}()
+
+	// The fake connection doesn't implement driver.ConnBeginTx: Begin must be used instead.
+	tx, err := db.BeginTx(ctx, nil)
+	is.NoError(err)
+	is.NoError(tx.Commit())
+
+	tx, err = db.BeginTx(ctx, nil)
+	is.NoError(err)
+	is.NoError(tx.Rollback())
+
+	is.Equal([]string{"begin", "commit", "begin", "rollback"}, events)
+}
@@ -0,0 +1,353 @@
+package makroud
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ulule/loukoum/v3/builder"
+)
+
+// ModelRows is an iterator over the instances of a model returned by a query.
+// Unlike Select, instances are not retrieved in memory at once: they are scanned one by one from the
+// database connection.
+//
+// A ModelRows must be closed once the iteration is completed.
+type ModelRows struct {
+	schema   *Schema
+	rows     Rows
+	handlers []JoinPreloadHandler
+	keys     map[interface{}]struct{}
+}
+
+// Stream executes a query retrieving every instance of given model matching given arguments, and returns an
+// iterator over these instances.
+// This function accepts the same arguments as Select.
+// Please note that the default query timeout of the driver, if any, applies to the whole iteration: use
+// WithoutTimeout if required.
+func Stream(ctx context.Context, driver Driver, model Model, args ...interface{}) (*ModelRows, error) {
+	schema, err := GetSchema(driver, model)
+	if err != nil {
+		return nil, errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", model)
+	}
+
+	handlers := getJoinPreloadHandlers(args)
+
+	query, err := getSelectRowsBuilder(schema, handlers, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return stream(ctx, driver, schema, query, handlers)
+}
+
+func stream(ctx context.Context, driver Driver, schema *Schema,
+	stmt builder.Builder, handlers []JoinPreloadHandler) (*ModelRows, error) {
+
+	keys, err := getJoinPreloadKeys(schema, handlers)
+	if err != nil {
+		return nil, err
+	}
+
+	query := NewQuery(stmt)
+	query.Query = getDialect(driver).Rebind(query.Query)
+
+	return streamQuery(ctx, driver, schema, query, handlers, keys)
+}
+
+// streamQuery executes given query and returns an iterator over its rows.
+// The primary keys of join preloaded models are recorded in given set, if any, see getJoinPreloadKeys.
+func streamQuery(ctx context.Context, driver Driver, schema *Schema,
+	query Query, handlers []JoinPreloadHandler, keys map[interface{}]struct{}) (*ModelRows, error) {
+
+	if isTraced(ctx, driver) {
+		start := time.Now()
+
+		defer func() {
+			trace(ctx, driver, query, time.Since(start))
+		}()
+	}
+
+	rows, err := driver.Query(ctx, query.Query, query.Args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ModelRows{
+		schema:   schema,
+		rows:     rows,
+		handlers: handlers,
+		keys:     keys,
+	}, nil
+}
+
+// Next prepares the next instance for reading with the Scan method.
+// It returns false if there is no more instances, or if an error happened: Err should be consulted to
+// distinguish between the two cases.
+func (rows *ModelRows) Next() bool {
+	return rows.rows.Next()
+}
+
+// Scan copies the values of the current row into given model.
+func (rows *ModelRows) Scan(model Model) error {
+	if len(rows.handlers) > 0 {
+		return scanJoinPreload(rows.schema, rows.rows, model, rows.handlers, rows.keys)
+	}
+	return rows.schema.ScanRows(rows.rows, model)
+}
+
+// Err returns the error, if any, that was encountered during iteration.
+func (rows *ModelRows) Err() error {
+	return rows.rows.Err()
+}
+
+// Close closes the iterator, releasing its database connection.
+func (rows *ModelRows) Close() error {
+	return rows.rows.Close()
+}
+
+// IterateOption configures an iteration executed by Iterate.
+type IterateOption func(options *iterateOptions) error
+
+type iterateOptions struct {
+	reuse    bool
+	fetch    int
+	batch    int
+	preloads []PreloadHandler
+}
+
+// WithIterateReuse will reuse the same model instance for every row, instead of allocating a new one.
+// The instance given to the callback must not be retained after it returns.
+func WithIterateReuse() IterateOption {
+	return func(options *iterateOptions) error {
+		options.reuse = true
+		return nil
+	}
+}
+
+// WithIterateCursor will use a PostgreSQL server-side cursor, fetching given number of rows at a time.
+// If required, a transaction is created for the duration of the iteration.
+func WithIterateCursor(fetch int) IterateOption {
+	return func(options *iterateOptions) error {
+		if fetch <= 0 {
+			return errors.Errorf("makroud: cursor fetch size must be greater than zero: %d", fetch)
+		}
+		options.fetch = fetch
+		return nil
+	}
+}
+
+// WithIteratePreload will preload given associations every given number of rows, before executing the
+// callback on each of these rows.
+// It requires WithIterateCursor: preloads are executed between two fetches, on the connection of the cursor,
+// so that the iteration never requires a second connection.
+func WithIteratePreload(batch int, handlers ...PreloadHandler) IterateOption {
+	return func(options *iterateOptions) error {
+		if batch <= 0 {
+			return errors.Errorf("makroud: preload batch size must be greater than zero: %d", batch)
+		}
+		options.batch = batch
+		options.preloads = handlers
+		return nil
+	}
+}
+
+// Iterate executes given callback on every instance of T matching given arguments, without retrieving them
+// in memory at once.
+// This function accepts the same arguments as Select, and IterateOption to configure the iteration.
+// If the callback returns an error, the iteration is stopped and this error is returned.
+func Iterate[T any, PT ModelPointer[T]](ctx context.Context, driver Driver,
+	callback func(PT) error, args ...interface{}) error {
+
+	options := iterateOptions{}
+	conditions := make([]interface{}, 0, len(args))
+	for i := range args {
+		option, ok := args[i].(IterateOption)
+		if !ok {
+			conditions = append(conditions, args[i])
+			continue
+		}
+		err := option(&options)
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.reuse && options.batch > 0 {
+		return errors.New("makroud: cannot reuse model instance with a batch preload")
+	}
+	if options.batch > 0 && options.fetch == 0 {
+		return errors.New("makroud: cannot use a batch preload without a cursor")
+	}
+
+	schema, err := GetSchema(driver, PT(new(T)))
+	if err != nil {
+		return errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", new(T))
+	}
+
+	handler := &iterateHandler[T, PT]{
+		options:  options,
+		callback: callback,
+	}
+
+	handlers := getJoinPreloadHandlers(conditions)
+
+	query, err := getSelectRowsBuilder(schema, handlers, conditions)
+	if err != nil {
+		return err
+	}
+
+	if options.fetch > 0 {
+		if driver.InTransaction() {
+			return handler.cursor(ctx, driver, schema, query, handlers)
+		}
+		return Transaction(ctx, driver, &TxOptions{ReadOnly: true}, func(tx Driver) error {
+			return handler.cursor(ctx, tx, schema, query, handlers)
+		})
+	}
+
+	rows, err := stream(ctx, driver, schema, query, handlers)
+	if err != nil {
+		return err
+	}
+	defer close(driver, rows, map[string]string{
+		"name":   schema.ModelName(),
+		"action": "iterate",
+	})
+
+	_, err = handler.consume(rows)
+	return err
+}
+
+// iterateCursorID is used to generate unique cursor names.
+var iterateCursorID uint64
+
+// iterateHandler executes a callback on rows consumed from ModelRows.
+type iterateHandler[T any, PT ModelPointer[T]] struct {
+	options  iterateOptions
+	callback func(PT) error
+	instance PT
+	pending  []PT
+}
+
+// consume scans every row of given iterator and returns the number of rows scanned.
+// If a batch preload is used, instances are kept pending until they are flushed, once given rows are closed.
+func (handler *iterateHandler[T, PT]) consume(rows *ModelRows) (int, error) {
+
+	count := 0
+
+	for rows.Next() {
+		count++
+
+		instance := handler.instance
+		if instance == nil || !handler.options.reuse {
+			instance = PT(new(T))
+			handler.instance = instance
+		} else {
+			*instance = *new(T)
+		}
+
+		err := rows.Scan(instance)
+		if err != nil {
+			return count, err
+		}
+
+		if handler.options.batch == 0 {
+			err = handler.callback(instance)
+			if err != nil {
+				return count, err
+			}
+			continue
+		}
+
+		handler.pending = append(handler.pending, instance)
+	}
+
+	return count, rows.Err()
+}
+
+// flush preloads pending instances, if any, and executes the callback on each of them.
+func (handler *iterateHandler[T, PT]) flush(ctx context.Context, driver Driver) error {
+	if len(handler.pending) == 0 {
+		return nil
+	}
+
+	err := Preload(ctx, driver, &handler.pending, handler.options.preloads...)
+	if err != nil {
+		return err
+	}
+
+	for i := range handler.pending {
+		err = handler.callback(handler.pending[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	handler.pending = handler.pending[:0]
+
+	return nil
+}
+
+// cursor consumes rows from a server-side cursor.
+// Given driver must be in a transaction.
+// The cursor is closed even if an error occurs, so that it doesn't outlive the iteration in the transaction.
+func (handler *iterateHandler[T, PT]) cursor(ctx context.Context, driver Driver, schema *Schema,
+	stmt builder.Select, handlers []JoinPreloadHandler) (err error) {
+
+	name := fmt.Sprintf("makroud_cursor_%d", atomic.AddUint64(&iterateCursorID, 1))
+
+	query := NewQuery(stmt)
+	query.Query = getDialect(driver).Rebind(query.Query)
+	query.Query = fmt.Sprint("DECLARE ", name, " NO SCROLL CURSOR FOR ", query.Query)
+
+	err = RawExecArgs(ctx, driver, query.Query, query.Args)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		thr := RawExec(ctx, driver, fmt.Sprint("CLOSE ", name))
+		if err == nil {
+			err = thr
+		}
+	}()
+
+	keys, err := getJoinPreloadKeys(schema, handlers)
+	if err != nil {
+		return err
+	}
+
+	fetch := NewRawQuery(fmt.Sprintf("FETCH %d FROM %s", handler.options.fetch, name))
+
+	for {
+		rows, err := streamQuery(ctx, driver, schema, fetch, handlers, keys)
+		if err != nil {
+			return err
+		}
+
+		count, err := handler.consume(rows)
+		thr := rows.Close()
+		if err != nil {
+			return err
+		}
+		if thr != nil {
+			return errors.Wrap(thr, "makroud: cannot close cursor rows")
+		}
+
+		if len(handler.pending) >= handler.options.batch {
+			err = handler.flush(ctx, driver)
+			if err != nil {
+				return err
+			}
+		}
+
+		if count < handler.options.fetch {
+			break
+		}
+	}
+
+	return handler.flush(ctx, driver)
+}
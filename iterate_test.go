@@ -0,0 +1,143 @@
+package makroud_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+func TestStream(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+		fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+		rows, err := makroud.Stream(ctx, driver, &Owl{}, loukoum.Condition("group_id").IsNull(false))
+		is.NoError(err)
+		is.NotNil(rows)
+
+		ids := []int64{}
+		for rows.Next() {
+			owl := &Owl{}
+			err = rows.Scan(owl)
+			is.NoError(err)
+			ids = append(ids, owl.ID)
+		}
+		is.NoError(rows.Err())
+		is.NoError(rows.Close())
+
+		is.Equal([]int64{
+			fixtures.Owls[0].ID, fixtures.Owls[2].ID, fixtures.Owls[3].ID,
+			fixtures.Owls[4].ID, fixtures.Owls[5].ID,
+		}, ids)
+	})
+}
+
+func TestIterate(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+		fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+		expected := []string{}
+		for _, owl := range fixtures.Owls {
+			expected = append(expected, owl.Name)
+		}
+
+		scenarios := []struct {
+			options []interface{}
+			reuse   bool
+		}{
+			{options: []interface{}{}},
+			{options: []interface{}{makroud.WithIterateReuse()}, reuse: true},
+			{options: []interface{}{makroud.WithIterateCursor(4)}},
+			{options: []interface{}{makroud.WithIterateCursor(2), makroud.WithIterateReuse()}, reuse: true},
+		}
+
+		for _, scenario := range scenarios {
+			instances := map[*Owl]bool{}
+			names := []string{}
+
+			err := makroud.Iterate(ctx, driver, func(owl *Owl) error {
+				instances[owl] = true
+				names = append(names, owl.Name)
+				return nil
+			}, scenario.options...)
+			is.NoError(err)
+			is.Equal(expected, names)
+
+			if scenario.reuse {
+				is.Len(instances, 1)
+			} else {
+				is.Len(instances, len(expected))
+			}
+		}
+
+		groups := 0
+		err := makroud.Iterate(ctx, driver, func(owl *Owl) error {
+			if owl.Group != nil {
+				groups++
+			}
+			return nil
+		}, makroud.WithIteratePreload(4, makroud.WithPreloadField("Group")), makroud.WithIterateCursor(3))
+		is.NoError(err)
+		is.Equal(5, groups)
+
+		// A batch preload requires a cursor.
+		err = makroud.Iterate(ctx, driver, func(owl *Owl) error {
+			return nil
+		}, makroud.WithIteratePreload(4, makroud.WithPreloadField("Group")))
+		is.Error(err)
+
+		stop := errors.New("stop")
+		count := 0
+		err = makroud.Iterate(ctx, driver, func(cat *Cat) error {
+			count++
+			return stop
+		})
+		is.Equal(stop, errors.Cause(err))
+		is.Equal(1, count)
+
+		// Cursor is closed when the iteration is stopped within a transaction.
+		err = makroud.Transaction(ctx, driver, nil, func(tx makroud.Driver) error {
+			err := makroud.Iterate(ctx, tx, func(cat *Cat) error {
+				return stop
+			}, makroud.WithIterateCursor(2))
+			is.Equal(stop, errors.Cause(err))
+
+			cursors, err := makroud.Count(ctx, tx, loukoum.Select("COUNT(*)").From("pg_cursors"))
+			is.NoError(err)
+			is.Equal(int64(0), cursors)
+			return nil
+		})
+		is.NoError(err)
+
+		err = makroud.Iterate(ctx, driver, func(cat *Cat) error {
+			return nil
+		}, makroud.WithIterateReuse(), makroud.WithIteratePreload(10, makroud.WithPreloadField("Meows")))
+		is.Error(err)
+	})
+}
+
+func TestIterate_SingleConnection(t *testing.T) {
+	Setup(t, makroud.MaxOpenConnections(1))(func(driver makroud.Driver) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		is := require.New(t)
+		fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+		meows := 0
+		err := makroud.Iterate(ctx, driver, func(cat *Cat) error {
+			meows += len(cat.Meows)
+			return nil
+		}, makroud.WithIteratePreload(2, makroud.WithPreloadField("Meows")), makroud.WithIterateCursor(3))
+		is.NoError(err)
+		is.Equal(len(fixtures.Meows), meows)
+	})
+}
@@ -0,0 +1,316 @@
+package makroud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/builder"
+	"github.com/ulule/loukoum/v3/stmt"
+	"github.com/ulule/loukoum/v3/types"
+
+	"github.com/ulule/makroud/reflectx"
+	"github.com/ulule/makroud/snaker"
+)
+
+// JoinPreloadHandler defines an association that should be eagerly loaded, using a LEFT JOIN in the query
+// retrieving its parent.
+type JoinPreloadHandler struct {
+	field string
+}
+
+// WithJoinPreload returns a handler that eagerly loads given one-to-one association using a LEFT JOIN, instead
+// of an extra query.
+// It can be used as an argument of Select, Find, FindAll, Stream or Iterate.
+// If the association doesn't exist, or if it's archived, the field is left with a zero value.
+//
+// The association must match at most one instance: if its foreign key is defined on the association table and
+// several instances match a parent, an ErrPreloadInvalidModel error is returned.
+//
+// Since the association table is joined, please use column paths, such as "table.column", in conditions on a
+// column name shared by both tables.
+func WithJoinPreload(field string) JoinPreloadHandler {
+	return JoinPreloadHandler{
+		field: field,
+	}
+}
+
+// getJoinPreloadHandlers returns every join preload handler from given select arguments.
+func getJoinPreloadHandlers(args []interface{}) []JoinPreloadHandler {
+	handlers := []JoinPreloadHandler{}
+	for i := range args {
+		handler, ok := args[i].(JoinPreloadHandler)
+		if ok {
+			handlers = append(handlers, handler)
+		}
+	}
+	return handlers
+}
+
+// getJoinPreloadReference returns the association reference for given handler.
+func getJoinPreloadReference(schema *Schema, handler JoinPreloadHandler) (Reference, error) {
+	reference, ok := schema.associations[handler.field]
+	if !ok {
+		return reference, errors.Wrapf(ErrSchemaInvalidAssociation,
+			"makroud: cannot join preload '%s' on %s", handler.field, schema.ModelName())
+	}
+
+	if !reference.IsAssociationType(AssociationTypeOne) {
+		return reference, errors.Wrapf(ErrPreloadInvalidPath,
+			"makroud: cannot join preload '%s' on %s, a one-to-one association is required",
+			handler.field, schema.ModelName())
+	}
+
+	return reference, nil
+}
+
+// getJoinPreloadAlias returns the table alias of given association in a join preload.
+func getJoinPreloadAlias(field string) string {
+	return fmt.Sprint("makroud_join_", snaker.CamelToSnake(field))
+}
+
+// newSelectBuilder returns a select builder for every columns of given schema, and of its join preloaded
+// associations.
+func newSelectBuilder(schema *Schema, handlers []JoinPreloadHandler) (builder.Select, error) {
+	columns := []interface{}{}
+	for _, column := range schema.ColumnPaths() {
+		columns = append(columns, column)
+	}
+
+	references := make([]Reference, 0, len(handlers))
+	for _, handler := range handlers {
+		reference, err := getJoinPreloadReference(schema, handler)
+		if err != nil {
+			return builder.Select{}, err
+		}
+
+		alias := getJoinPreloadAlias(handler.field)
+		for _, column := range reference.Remote().Columns() {
+			name := fmt.Sprint(`"`, handler.field, ".", column, `"`)
+			columns = append(columns, loukoum.Column(fmt.Sprint(alias, ".", column)).As(name))
+		}
+
+		references = append(references, reference)
+	}
+
+	query := loukoum.Select(columns...).From(schema.TableName())
+
+	for i, reference := range references {
+		local := reference.Local()
+		remote := reference.Remote()
+		alias := getJoinPreloadAlias(handlers[i].field)
+
+		condition := joinPreloadCondition{
+			OnClause: loukoum.On(local.ColumnPath(), fmt.Sprint(alias, ".", remote.ColumnName())),
+		}
+		if remote.Schema().HasDeletedKey() {
+			condition.deleted = fmt.Sprint(alias, ".", remote.Schema().DeletedKeyName())
+		}
+
+		table := loukoum.Table(remote.TableName()).As(alias)
+		query = query.Join(stmt.NewLeftJoin(table, condition))
+	}
+
+	return query, nil
+}
+
+// joinPreloadCondition is the ON clause of a join preload, which excludes archived associations.
+type joinPreloadCondition struct {
+	stmt.OnClause
+	deleted string
+}
+
+// Write exposes statement as a SQL query.
+func (condition joinPreloadCondition) Write(ctx types.Context) {
+	condition.OnClause.Write(ctx)
+	if condition.deleted != "" {
+		ctx.Write(fmt.Sprint(" AND ", condition.deleted, " IS NULL"))
+	}
+}
+
+// getJoinPreloadKeys returns a set to record the primary key of every model retrieved with given handlers, if
+// one of their associations has its foreign key on the association table, and may match several instances.
+// Otherwise, it returns nil.
+func getJoinPreloadKeys(schema *Schema, handlers []JoinPreloadHandler) (map[interface{}]struct{}, error) {
+	for _, handler := range handlers {
+		reference, err := getJoinPreloadReference(schema, handler)
+		if err != nil {
+			return nil, err
+		}
+		if !reference.Remote().IsPrimaryKey() {
+			return map[interface{}]struct{}{}, nil
+		}
+	}
+	return nil, nil
+}
+
+// execJoinPreload executes given query, generated with join preload handlers, and scans its rows into dest.
+func execJoinPreload(ctx context.Context, driver Driver, schema *Schema, query builder.Select,
+	dest interface{}, handlers []JoinPreloadHandler) error {
+
+	flags := map[string]string{
+		"name":   schema.ModelName(),
+		"action": "exec-join-preload",
+	}
+
+	return queryRows(ctx, driver, query, dest, flags, func(rows Rows) error {
+		if reflectx.IsSlice(dest) {
+			base := reflectx.GetIndirectSliceType(dest)
+			list := reflectx.GetIndirectValue(dest)
+
+			keys, err := getJoinPreloadKeys(schema, handlers)
+			if err != nil {
+				return err
+			}
+
+			for rows.Next() {
+				model := reflectx.NewValue(base).(Model)
+
+				err = scanJoinPreload(schema, rows, model, handlers, keys)
+				if err != nil {
+					return err
+				}
+
+				reflectx.AppendReflectSlice(list, model)
+			}
+
+			return nil
+		}
+
+		if !rows.Next() {
+			err := rows.Err()
+			if err != nil {
+				return err
+			}
+			return ErrNoRows
+		}
+
+		model := reflectx.GetFlattenValue(dest).(Model)
+
+		return scanJoinPreload(schema, rows, model, handlers, nil)
+	})
+}
+
+// checkJoinPreloadKey returns an error if given model has already been retrieved, since one of its join
+// preloaded associations matches more than one instance.
+func checkJoinPreloadKey(schema *Schema, model Model, keys map[interface{}]struct{}) error {
+	key, err := schema.PrimaryKey().Value(model)
+	if err != nil {
+		return err
+	}
+
+	_, ok := keys[key]
+	if ok {
+		return errors.Wrapf(ErrPreloadInvalidModel,
+			"makroud: cannot join preload on %s, more than one instance is associated to %v", schema.ModelName(), key)
+	}
+
+	keys[key] = struct{}{}
+	return nil
+}
+
+// scanJoinPreload scans current row into given model, and its join preloaded associations.
+// If keys is not nil, an error is returned if the model has already been retrieved, see getJoinPreloadKeys.
+//
+// Since every column of an association is NULL if it doesn't exist, the row is scanned twice: the first scan
+// retrieves association columns in placeholders, to find which associations exist, and the second one scans
+// these associations into the model.
+func scanJoinPreload(schema *Schema, rows Rows, model Model, handlers []JoinPreloadHandler,
+	keys map[interface{}]struct{}) error {
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	value := reflectx.GetIndirectValue(model)
+	if !reflectx.IsStruct(value) {
+		return errors.Wrapf(ErrStructRequired, "cannot use mapper on %T", model)
+	}
+
+	values, err := schema.getValues(value, columns, model)
+	if err != nil {
+		return err
+	}
+
+	placeholders := make([]interface{}, len(values))
+	copy(placeholders, values)
+
+	type association struct {
+		reference Reference
+		indexes   []int
+		pk        int
+	}
+
+	associations := make([]association, 0, len(handlers))
+	for _, handler := range handlers {
+		reference, err := getJoinPreloadReference(schema, handler)
+		if err != nil {
+			return err
+		}
+
+		remote := reference.Remote().Schema()
+		element := association{
+			reference: reference,
+			pk:        -1,
+		}
+
+		prefix := fmt.Sprint(handler.field, ".")
+		for i, column := range columns {
+			if !strings.HasPrefix(column, prefix) {
+				continue
+			}
+
+			name := strings.TrimPrefix(column, prefix)
+			if name == remote.PrimaryKeyName() {
+				element.pk = i
+			}
+
+			element.indexes = append(element.indexes, i)
+			placeholders[i] = new(interface{})
+		}
+
+		if element.pk < 0 {
+			return errors.Wrapf(ErrSchemaColumnRequired,
+				"makroud: cannot find primary key of '%s' in join preload", handler.field)
+		}
+
+		associations = append(associations, element)
+	}
+
+	err = rows.Scan(placeholders...)
+	if err != nil {
+		return err
+	}
+
+	if keys != nil {
+		err = checkJoinPreloadKey(schema, model, keys)
+		if err != nil {
+			return err
+		}
+	}
+
+	rescan := false
+	for _, element := range associations {
+		found := *(placeholders[element.pk].(*interface{})) != nil
+		if !found {
+			field := reflect.Indirect(value).FieldByIndex(element.reference.FieldIndex())
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+
+		for _, i := range element.indexes {
+			placeholders[i] = values[i]
+		}
+		rescan = true
+	}
+
+	if !rescan {
+		return nil
+	}
+
+	return rows.Scan(placeholders...)
+}
@@ -0,0 +1,178 @@
+package makroud_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+func TestJoinPreload(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+		fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+		{
+			owls := []Owl{}
+			err := makroud.Select(ctx, driver, &owls,
+				makroud.WithJoinPreload("Group"), makroud.WithJoinPreload("Bag"))
+			is.NoError(err)
+			is.Len(owls, len(fixtures.Owls))
+
+			for i := range owls {
+				is.Equal(fixtures.Owls[i].ID, owls[i].ID)
+				is.Equal(fixtures.Owls[i].Name, owls[i].Name)
+
+				if fixtures.Owls[i].GroupID.Valid {
+					is.NotNil(owls[i].Group)
+					is.Equal(fixtures.Owls[i].GroupID.Int64, owls[i].Group.ID)
+				} else {
+					is.Nil(owls[i].Group)
+				}
+			}
+
+			is.NotNil(owls[0].Bag)
+			is.Equal("Frosty Cyan", owls[0].Bag.Color)
+			is.Equal(owls[0].ID, owls[0].Bag.OwlID)
+			is.Nil(owls[2].Bag)
+		}
+		{
+			owl := &Owl{}
+			err := makroud.Select(ctx, driver, owl,
+				loukoum.Condition("ztp_owl.name").Equal("Bungee"),
+				makroud.WithJoinPreload("Group"))
+			is.NoError(err)
+			is.Equal(fixtures.Owls[1].ID, owl.ID)
+			is.Nil(owl.Group)
+
+			err = makroud.Select(ctx, driver, owl,
+				loukoum.Condition("ztp_owl.name").Equal("Hedwig"),
+				makroud.WithJoinPreload("Group"))
+			is.Error(err)
+			is.True(makroud.IsErrNoRows(err))
+		}
+		{
+			packages, err := makroud.FindAll[Package](ctx, driver,
+				makroud.WithJoinPreload("Sender"), makroud.WithJoinPreload("Receiver"))
+			is.NoError(err)
+			is.Len(packages, len(fixtures.Packages))
+
+			for i := range packages {
+				is.NotNil(packages[i].Sender)
+				is.NotNil(packages[i].Receiver)
+				is.Equal(packages[i].SenderID, packages[i].Sender.ID)
+				is.Equal(packages[i].ReceiverID, packages[i].Receiver.ID)
+			}
+		}
+		{
+			cat := &Cat{Name: "Orion"}
+			err := makroud.Save(ctx, driver, cat)
+			is.NoError(err)
+
+			human := &Human{
+				Name:  "Vivian",
+				CatID: sql.NullString{Valid: true, String: cat.ID},
+			}
+			err = makroud.Save(ctx, driver, human)
+			is.NoError(err)
+
+			result, err := makroud.Get[Human](ctx, driver, human.ID)
+			is.NoError(err)
+			is.Nil(result.Cat)
+
+			result, err = makroud.Find[Human](ctx, driver,
+				loukoum.Condition("ztp_human.id").Equal(human.ID), makroud.WithJoinPreload("Cat"))
+			is.NoError(err)
+			is.NotNil(result.Cat)
+			is.Equal(cat.ID, result.Cat.ID)
+			is.Equal("Orion", result.Cat.Name)
+
+			found, err := makroud.Find[Cat](ctx, driver,
+				loukoum.Condition("ztp_cat.id").Equal(cat.ID), makroud.WithJoinPreload("Feeder"))
+			is.NoError(err)
+			is.NotNil(found.Feeder)
+			is.Equal(human.ID, found.Feeder.ID)
+
+			err = makroud.Archive(ctx, driver, human)
+			is.NoError(err)
+
+			found, err = makroud.Find[Cat](ctx, driver,
+				loukoum.Condition("ztp_cat.id").Equal(cat.ID), makroud.WithJoinPreload("Feeder"))
+			is.NoError(err)
+			is.Equal(cat.ID, found.ID)
+			is.Nil(found.Feeder)
+
+			// An archived association is excluded by the join: it's not a duplicate of the current one.
+			feeder := &Human{
+				Name:  "Sir Isaac",
+				CatID: sql.NullString{Valid: true, String: cat.ID},
+			}
+			err = makroud.Save(ctx, driver, feeder)
+			is.NoError(err)
+
+			cats := []Cat{}
+			err = makroud.Select(ctx, driver, &cats,
+				loukoum.Condition("ztp_cat.id").Equal(cat.ID), makroud.WithJoinPreload("Feeder"))
+			is.NoError(err)
+			is.Len(cats, 1)
+			is.NotNil(cats[0].Feeder)
+			is.Equal(feeder.ID, cats[0].Feeder.ID)
+		}
+		{
+			bag := &Bag{
+				Color: "Spare Cyan",
+				OwlID: fixtures.Owls[0].ID,
+			}
+			err := makroud.Save(ctx, driver, bag)
+			is.NoError(err)
+
+			owls := []Owl{}
+			err = makroud.Select(ctx, driver, &owls, makroud.WithJoinPreload("Bag"))
+			is.Error(err)
+			is.Equal(makroud.ErrPreloadInvalidModel, errors.Cause(err))
+
+			rows, err := makroud.Stream(ctx, driver, &Owl{}, makroud.WithJoinPreload("Bag"))
+			is.NoError(err)
+			for rows.Next() {
+				err = rows.Scan(&Owl{})
+				if err != nil {
+					break
+				}
+			}
+			is.Error(err)
+			is.Equal(makroud.ErrPreloadInvalidModel, errors.Cause(err))
+			is.NoError(rows.Close())
+
+			for _, options := range [][]interface{}{
+				{makroud.WithJoinPreload("Bag")},
+				{makroud.WithJoinPreload("Bag"), makroud.WithIterateCursor(1)},
+			} {
+				err = makroud.Iterate(ctx, driver, func(owl *Owl) error {
+					return nil
+				}, options...)
+				is.Error(err)
+				is.Equal(makroud.ErrPreloadInvalidModel, errors.Cause(err))
+			}
+
+			err = makroud.Delete(ctx, driver, bag)
+			is.NoError(err)
+		}
+		{
+			owls := []Owl{}
+
+			err := makroud.Select(ctx, driver, &owls, makroud.WithJoinPreload("Nest"))
+			is.Error(err)
+			is.Equal(makroud.ErrSchemaInvalidAssociation, errors.Cause(err))
+
+			err = makroud.Select(ctx, driver, &owls, makroud.WithJoinPreload("Packages"))
+			is.Error(err)
+			is.Equal(makroud.ErrPreloadInvalidPath, errors.Cause(err))
+		}
+	})
+}
@@ -0,0 +1,342 @@
+package makroud
+
+import (
+	"bytes"
+	"context"
+	sqldriver "database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/stmt"
+
+	"github.com/ulule/makroud/reflectx"
+)
+
+// Keyset defines a keyset (or cursor) pagination on a model.
+type Keyset struct {
+	// Orders defines the ordered columns used to paginate.
+	// The model primary key is always appended, if missing, to handle ties.
+	// By default, instances are ordered by their primary key.
+	Orders []stmt.Order
+	// Limit defines the maximum number of instances on a page.
+	Limit int64
+	// Cursor is the opaque token, returned by a previous page, of the requested page.
+	// If empty, the first page is returned.
+	Cursor string
+}
+
+// KeysetPage contains the cursors of the pages around a page returned by PaginateKeyset.
+type KeysetPage struct {
+	// Next is the cursor of the next page, or an empty string if there is no next page.
+	Next string
+	// Previous is the cursor of the previous page, or an empty string if there is no previous page.
+	Previous string
+}
+
+// HasNext returns if there is a next page.
+func (page KeysetPage) HasNext() bool {
+	return page.Next != ""
+}
+
+// HasPrevious returns if there is a previous page.
+func (page KeysetPage) HasPrevious() bool {
+	return page.Previous != ""
+}
+
+// PaginateKeyset retrieves a page of instances, defined by given keyset, into given slice.
+// This function accepts loukoum's stmt.Expression as arguments to filter instances.
+// Columns used by the keyset must not be nullable.
+func PaginateKeyset(ctx context.Context, driver Driver, dest interface{},
+	keyset Keyset, args ...interface{}) (*KeysetPage, error) {
+
+	if !reflectx.IsPointer(dest) || !reflectx.IsSlice(dest) {
+		return nil, errors.Wrapf(ErrPointerRequired, "makroud: cannot execute query on %T", dest)
+	}
+
+	model, ok := reflectx.NewSliceValue(dest).(Model)
+	if !ok {
+		return nil, errors.Wrapf(ErrModelRequired, "makroud: cannot execute query on %T", dest)
+	}
+
+	schema, err := GetSchema(driver, model)
+	if err != nil {
+		return nil, errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", dest)
+	}
+
+	if keyset.Limit <= 0 {
+		return nil, errors.Errorf("makroud: invalid keyset limit: %d", keyset.Limit)
+	}
+
+	orders, err := getKeysetOrders(schema, keyset.Orders)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := keysetCursor{}
+	if keyset.Cursor != "" {
+		cursor, err = decodeKeysetCursor(keyset.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Orders != getKeysetFingerprint(schema, orders) || len(cursor.Values) != len(orders) {
+			return nil, errors.Wrap(ErrInvalidCursor, "makroud: cursor doesn't match keyset orders")
+		}
+	}
+
+	backward := cursor.Direction == keysetDirectionPrevious
+	if backward {
+		orders = reverseKeysetOrders(orders)
+	}
+
+	query := loukoum.Select(schema.ColumnPaths().List()).From(model.TableName())
+	for i := range args {
+		condition, ok := args[i].(stmt.Expression)
+		if ok {
+			query = query.Where(condition)
+		}
+	}
+	if schema.HasDeletedKey() {
+		query = query.Where(loukoum.Condition(schema.DeletedKeyPath()).IsNull(true))
+	}
+	if len(cursor.Values) > 0 {
+		query = query.Where(getKeysetCondition(orders, cursor.Values))
+	}
+	query = query.OrderBy(orders...).Limit(keyset.Limit + 1)
+
+	// Instances are appended to given slice: only the ones returned by this query belong to the page.
+	slice := reflect.Indirect(reflect.ValueOf(dest))
+	offset := slice.Len()
+
+	err = Exec(ctx, driver, query, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	more := int64(slice.Len()-offset) > keyset.Limit
+	if more {
+		slice.Set(slice.Slice(0, offset+int(keyset.Limit)))
+	}
+
+	list := slice.Slice(offset, slice.Len())
+	if backward {
+		reverseSlice(list)
+	}
+
+	page := &KeysetPage{}
+	size := list.Len()
+	if size == 0 {
+		return page, nil
+	}
+
+	if more || backward {
+		page.Next, err = encodeKeysetCursor(schema, keysetDirectionNext, keyset.Orders, list.Index(size-1))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if (backward && more) || (!backward && len(cursor.Values) > 0) {
+		page.Previous, err = encodeKeysetCursor(schema, keysetDirectionPrevious, keyset.Orders, list.Index(0))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+const (
+	keysetDirectionNext     = "next"
+	keysetDirectionPrevious = "previous"
+)
+
+// keysetCursor is the decoded version of a keyset cursor.
+// It contains the orders used to create it, so a cursor can't be used with another keyset.
+type keysetCursor struct {
+	Direction string        `json:"d"`
+	Orders    string        `json:"o"`
+	Values    []interface{} `json:"v"`
+}
+
+func decodeKeysetCursor(token string) (keysetCursor, error) {
+	cursor := keysetCursor{}
+
+	buffer, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, errors.Wrap(ErrInvalidCursor, "makroud: cannot decode cursor")
+	}
+
+	// Numbers are kept as json.Number to avoid a loss of precision on large integers.
+	decoder := json.NewDecoder(bytes.NewReader(buffer))
+	decoder.UseNumber()
+
+	err = decoder.Decode(&cursor)
+	if err != nil {
+		return cursor, errors.Wrap(ErrInvalidCursor, "makroud: cannot decode cursor")
+	}
+
+	if cursor.Direction != keysetDirectionNext && cursor.Direction != keysetDirectionPrevious {
+		return cursor, errors.Wrap(ErrInvalidCursor, "makroud: cannot decode cursor")
+	}
+
+	for i := range cursor.Values {
+		number, ok := cursor.Values[i].(json.Number)
+		if ok {
+			cursor.Values[i] = number.String()
+		}
+	}
+
+	return cursor, nil
+}
+
+func encodeKeysetCursor(schema *Schema, direction string, orders []stmt.Order, element reflect.Value) (string, error) {
+	orders, err := getKeysetOrders(schema, orders)
+	if err != nil {
+		return "", err
+	}
+
+	cursor := keysetCursor{
+		Direction: direction,
+		Orders:    getKeysetFingerprint(schema, orders),
+		Values:    make([]interface{}, 0, len(orders)),
+	}
+
+	for _, order := range orders {
+		value, err := getKeysetValue(schema, element, order.Expression)
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+
+	buffer, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.Wrap(err, "makroud: cannot encode cursor")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+// getKeysetOrders validates given orders and appends the primary key, if missing.
+func getKeysetOrders(schema *Schema, orders []stmt.Order) ([]stmt.Order, error) {
+	list := make([]stmt.Order, 0, len(orders)+1)
+	hasPK := false
+
+	for _, order := range orders {
+		if !schema.HasColumn(order.Expression) {
+			return nil, errors.Wrapf(ErrSchemaColumnRequired,
+				"makroud: cannot use column %s in keyset of %T", order.Expression, schema.Model())
+		}
+		if order.Expression == schema.PrimaryKeyName() || order.Expression == schema.PrimaryKeyPath() {
+			hasPK = true
+		}
+		list = append(list, order)
+	}
+
+	if !hasPK {
+		list = append(list, loukoum.Order(schema.PrimaryKeyPath()))
+	}
+
+	return list, nil
+}
+
+// getKeysetFingerprint returns the list of columns, with their direction, used by given orders.
+// For example: "name DESC,id ASC".
+func getKeysetFingerprint(schema *Schema, orders []stmt.Order) string {
+	list := make([]string, 0, len(orders))
+	for _, order := range orders {
+		column := strings.TrimPrefix(order.Expression, fmt.Sprint(schema.TableName(), "."))
+		direction := loukoum.Asc
+		if order.Type == loukoum.Desc {
+			direction = loukoum.Desc
+		}
+		list = append(list, fmt.Sprint(column, " ", direction))
+	}
+	return strings.Join(list, ",")
+}
+
+func reverseKeysetOrders(orders []stmt.Order) []stmt.Order {
+	list := make([]stmt.Order, len(orders))
+	for i, order := range orders {
+		if order.Type == loukoum.Desc {
+			list[i] = loukoum.Order(order.Expression, loukoum.Asc)
+		} else {
+			list[i] = loukoum.Order(order.Expression, loukoum.Desc)
+		}
+	}
+	return list
+}
+
+// getKeysetCondition returns the condition matching every row after given values, using given orders.
+// For example, with "a ASC, b DESC, id ASC", it returns:
+//
+//	(a > $1) OR (a = $1 AND b < $2) OR (a = $1 AND b = $2 AND id > $3)
+func getKeysetCondition(orders []stmt.Order, values []interface{}) stmt.Expression {
+	var condition stmt.Expression
+
+	for i, order := range orders {
+		var expression stmt.Expression
+		if order.Type == loukoum.Desc {
+			expression = loukoum.Condition(order.Expression).LessThan(values[i])
+		} else {
+			expression = loukoum.Condition(order.Expression).GreaterThan(values[i])
+		}
+
+		for j := i - 1; j >= 0; j-- {
+			expression = loukoum.And(loukoum.Condition(orders[j].Expression).Equal(values[j]), expression)
+		}
+
+		if condition == nil {
+			condition = expression
+		} else {
+			condition = loukoum.Or(condition, expression)
+		}
+	}
+
+	return condition
+}
+
+// getKeysetValue returns the value of given column on element, using its driver representation.
+func getKeysetValue(schema *Schema, element reflect.Value, column string) (interface{}, error) {
+	column = strings.TrimPrefix(column, fmt.Sprint(schema.TableName(), "."))
+
+	indexes := schema.pk.FieldIndex()
+	if column != schema.PrimaryKeyName() {
+		field, ok := schema.fields[column]
+		if !ok {
+			return nil, errors.Wrapf(ErrSchemaColumnRequired,
+				"makroud: cannot use column %s in keyset of %T", column, schema.Model())
+		}
+		indexes = field.FieldIndex()
+	}
+
+	value, err := reflectx.GetFieldValueWithIndexes(element, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	valuer, ok := value.(sqldriver.Valuer)
+	if ok {
+		value, err = valuer.Value()
+		if err != nil {
+			return nil, errors.Wrapf(err, "makroud: cannot use column %s in keyset", column)
+		}
+	}
+
+	if value == nil {
+		return nil, errors.Errorf("makroud: cannot use null value of column %s in keyset", column)
+	}
+
+	return value, nil
+}
+
+func reverseSlice(list reflect.Value) {
+	swap := reflect.Swapper(list.Interface())
+	for i, j := 0, list.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
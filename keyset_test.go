@@ -0,0 +1,170 @@
+package makroud_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/stmt"
+
+	"github.com/ulule/makroud"
+)
+
+func TestPaginateKeyset(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+		GenerateZootopiaFixtures(ctx, driver, is)
+
+		names := func(owls []Owl) []string {
+			list := []string{}
+			for i := range owls {
+				list = append(list, owls[i].Name)
+			}
+			return list
+		}
+
+		keyset := makroud.Keyset{
+			Orders: []stmt.Order{loukoum.Order("name", loukoum.Desc)},
+			Limit:  2,
+		}
+
+		owls := []Owl{}
+		page, err := makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+		is.NotNil(page)
+		is.Equal([]string{"Wacky", "Pyro"}, names(owls))
+		is.True(page.HasNext())
+		is.False(page.HasPrevious())
+
+		keyset.Cursor = page.Next
+		owls = []Owl{}
+		page, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+		is.Equal([]string{"Puffins", "Pistache"}, names(owls))
+		is.True(page.HasNext())
+		is.True(page.HasPrevious())
+
+		keyset.Cursor = page.Next
+		owls = []Owl{}
+		page, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+		is.Equal([]string{"Bungee", "Baloo"}, names(owls))
+		is.False(page.HasNext())
+		is.True(page.HasPrevious())
+
+		keyset.Cursor = page.Previous
+		owls = []Owl{}
+		page, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+		is.Equal([]string{"Puffins", "Pistache"}, names(owls))
+		is.True(page.HasNext())
+		is.True(page.HasPrevious())
+
+		keyset.Cursor = page.Previous
+		owls = []Owl{}
+		page, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+		is.Equal([]string{"Wacky", "Pyro"}, names(owls))
+		is.True(page.HasNext())
+		is.False(page.HasPrevious())
+
+		// Instances are appended to a non-empty slice, without changing the page.
+		keyset.Cursor = ""
+		owls = []Owl{{Name: "Hedwig"}, {Name: "Errol"}}
+		page, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+		is.Equal([]string{"Hedwig", "Errol", "Wacky", "Pyro"}, names(owls))
+		is.True(page.HasNext())
+		is.False(page.HasPrevious())
+
+		keyset.Cursor = page.Next
+		owls = []Owl{}
+		page, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+
+		keyset.Cursor = page.Previous
+		owls = []Owl{{Name: "Hedwig"}}
+		page, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+		is.Equal([]string{"Hedwig", "Wacky", "Pyro"}, names(owls))
+		is.True(page.HasNext())
+		is.False(page.HasPrevious())
+
+		keyset.Cursor = "invalid"
+		_, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.Error(err)
+		is.Equal(makroud.ErrInvalidCursor, errors.Cause(err))
+
+		// A cursor can't be used with other orders.
+		keyset.Cursor = page.Next
+		for _, orders := range [][]stmt.Order{
+			{loukoum.Order("name", loukoum.Asc)},
+			{loukoum.Order("favorite_food", loukoum.Desc)},
+			{loukoum.Order("name", loukoum.Desc), loukoum.Order("id", loukoum.Desc)},
+		} {
+			keyset.Orders = orders
+			_, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+			is.Error(err)
+			is.Equal(makroud.ErrInvalidCursor, errors.Cause(err))
+		}
+
+		keyset.Orders = []stmt.Order{loukoum.Order("ztp_owl.name", loukoum.Desc), loukoum.Order("id")}
+		owls = []Owl{}
+		_, err = makroud.PaginateKeyset(ctx, driver, &owls, keyset)
+		is.NoError(err)
+		is.Equal([]string{"Puffins", "Pistache"}, names(owls))
+	})
+}
+
+func TestPaginateKeyset_Ties(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		groups := []*Group{
+			{Name: "Alpha"},
+			{Name: "Beta"},
+			{Name: "Alpha"},
+			{Name: "Beta"},
+			{Name: "Alpha"},
+			{Name: "Gamma"},
+		}
+		for i := range groups {
+			err := makroud.Save(ctx, driver, groups[i])
+			is.NoError(err)
+		}
+
+		expected := []int64{
+			groups[4].ID, groups[2].ID, groups[0].ID,
+			groups[3].ID, groups[1].ID, groups[5].ID,
+		}
+
+		keyset := makroud.Keyset{
+			Orders: []stmt.Order{
+				loukoum.Order("name", loukoum.Asc),
+				loukoum.Order("id", loukoum.Desc),
+			},
+			Limit: 4,
+		}
+
+		ids := []int64{}
+		for {
+			list := []*Group{}
+			page, err := makroud.PaginateKeyset(ctx, driver, &list, keyset,
+				loukoum.Condition("name").NotEqual("Delta"))
+			is.NoError(err)
+			for i := range list {
+				ids = append(ids, list[i].ID)
+			}
+			if !page.HasNext() {
+				break
+			}
+			keyset.Cursor = page.Next
+		}
+
+		is.Equal(expected, ids)
+	})
+}
@@ -2,7 +2,9 @@ package makroud
 
 import (
 	"context"
+	"database/sql"
 	"io"
+	"time"
 )
 
 // Driver is a high level abstraction of a database connection or a transaction.
@@ -33,6 +35,10 @@ type Driver interface {
 	// Multiple queries or executions may be run concurrently from the returned statement.
 	Prepare(ctx context.Context, query string) (Statement, error)
 
+	// Explain returns the execution plan of given query, in JSON format.
+	// The plan is obtained using a dedicated connection, outside of any transaction.
+	Explain(ctx context.Context, query string, args ...interface{}) ([]byte, error)
+
 	// ----------------------------------------------------------------------------
 	// Connection
 	// ----------------------------------------------------------------------------
@@ -43,9 +49,15 @@ type Driver interface {
 	// Ping verifies that the underlying connection is healthy.
 	Ping() error
 
+	// PingContext verifies that the underlying connection is healthy.
+	PingContext(ctx context.Context) error
+
 	// DriverName returns the driver name used by this driver.
 	DriverName() string
 
+	// Stats returns the statistics of the underlying connection pool.
+	Stats() sql.DBStats
+
 	// ----------------------------------------------------------------------------
 	// Transaction
 	// ----------------------------------------------------------------------------
@@ -67,6 +79,19 @@ type Driver interface {
 	// Commit commits the associated transaction.
 	Commit() error
 
+	// InTransaction returns if the driver is bound to a transaction.
+	InTransaction() bool
+
+	// IsNested returns if the transaction of the driver is nested in another one.
+	IsNested() bool
+
+	// NestingDepth returns the number of transactions the driver is bound to: zero outside of a transaction, one
+	// in a transaction and more than one in a nested transaction.
+	NestingDepth() int
+
+	// SavepointName returns the savepoint name of the nested transaction of the driver, if savepoints are enabled.
+	SavepointName() string
+
 	// ----------------------------------------------------------------------------
 	// System
 	// ----------------------------------------------------------------------------
@@ -102,6 +127,36 @@ type Driver interface {
 	// WARNING: Please, do not use this method unless you know what you are doing.
 	Observer() Observer
 
+	// SlowQueryThreshold returns the duration after which a query is reported as slow to the observer.
+	// A zero duration means that slow query detection is disabled.
+	SlowQueryThreshold() time.Duration
+
+	// HasSlowQueryExplain returns if the execution plan should be attached to a slow query report.
+	HasSlowQueryExplain() bool
+
+	// NPlusOneThreshold returns how many times a query shape can be executed within a query scope before
+	// being reported as a N+1 query to the observer.
+	// A zero value means that N+1 detection is disabled.
+	NPlusOneThreshold() int
+
+	// Dialect returns the SQL dialect of the database engine.
+	Dialect() Dialect
+
+	// QueryTimeout returns the timeout applied on a query if its context has no deadline.
+	// A zero duration means that there is no default timeout.
+	QueryTimeout() time.Duration
+
+	// TransactionTimeout returns the timeout applied on a transaction if its context has no deadline.
+	// A zero duration means that there is no default timeout.
+	TransactionTimeout() time.Duration
+
+	// RetryPolicy returns the policy used to retry idempotent reads after a transient connection error.
+	RetryPolicy() RetryPolicy
+
+	// PreloadBatchSize returns the maximum number of keys used by a single preload query.
+	// A preload with more keys is split in multiple queries.
+	PreloadBatchSize() int
+
 	// Entropy returns an entropy source, used for primary key generation (if required).
 	//
 	// WARNING: Please, do not use this method unless you know what you are doing.
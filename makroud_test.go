@@ -3274,6 +3274,8 @@ type Cat struct {
 	// Relationships
 	Feeder *Human  `makroud:"relation:ztp_human.cat_id"`
 	Meows  []*Meow `makroud:"relation:ztp_meow.cat_id"`
+	// Aggregates
+	MeowsCount int64 `makroud:"-"`
 }
 
 func (Cat) TableName() string {
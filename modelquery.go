@@ -0,0 +1,415 @@
+package makroud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/builder"
+	"github.com/ulule/loukoum/v3/stmt"
+
+	"github.com/ulule/makroud/reflectx"
+)
+
+// ModelQuery is a query bound to a model schema.
+// Every column used by this query is validated against the model schema, or against the schema of a joined
+// association, when the query is built.
+// A ModelQuery is immutable: every method returns a new instance.
+type ModelQuery struct {
+	model    Model
+	columns  []string
+	wheres   []stmt.Expression
+	orders   []stmt.Order
+	groups   []string
+	joins    []string
+	preloads []PreloadHandler
+	limit    interface{}
+	offset   interface{}
+	unscoped bool
+}
+
+// From returns a new query bound to given model.
+func From(model Model) ModelQuery {
+	return ModelQuery{
+		model: model,
+	}
+}
+
+// Select restricts the query projection to given columns.
+// By default, every column of the model is retrieved.
+func (query ModelQuery) Select(columns ...string) ModelQuery {
+	query.columns = append(query.columns[:len(query.columns):len(query.columns)], columns...)
+	return query
+}
+
+// Where adds given conditions to the query.
+func (query ModelQuery) Where(conditions ...stmt.Expression) ModelQuery {
+	query.wheres = append(query.wheres[:len(query.wheres):len(query.wheres)], conditions...)
+	return query
+}
+
+// OrderBy adds given orders to the query.
+// By default, the query is ordered by the model primary key.
+func (query ModelQuery) OrderBy(orders ...stmt.Order) ModelQuery {
+	query.orders = append(query.orders[:len(query.orders):len(query.orders)], orders...)
+	return query
+}
+
+// GroupBy groups the rows of the query by given columns.
+func (query ModelQuery) GroupBy(columns ...string) ModelQuery {
+	query.groups = append(query.groups[:len(query.groups):len(query.groups)], columns...)
+	return query
+}
+
+// Join adds an INNER JOIN on given associations, using their names in the model.
+// Columns of a joined association can be used in conditions or orders with their full path, such as
+// "table.column", and columns of the model are then qualified with its table.
+// If a many association is joined, rows are grouped by the model primary key so that every instance is
+// returned once, unless the query is explicitly grouped: its columns can't be used in orders.
+// A one association is expected to match at most one row.
+func (query ModelQuery) Join(associations ...string) ModelQuery {
+	query.joins = append(query.joins[:len(query.joins):len(query.joins)], associations...)
+	return query
+}
+
+// Limit defines the maximum number of rows returned by the query.
+func (query ModelQuery) Limit(limit interface{}) ModelQuery {
+	query.limit = limit
+	return query
+}
+
+// Offset defines the number of rows to skip before returning rows.
+func (query ModelQuery) Offset(offset interface{}) ModelQuery {
+	query.offset = offset
+	return query
+}
+
+// Unscoped disables the deleted key filter, for the model and its joined associations.
+func (query ModelQuery) Unscoped() ModelQuery {
+	query.unscoped = true
+	return query
+}
+
+// Preload defines which associations should be preloaded once the query is executed.
+func (query ModelQuery) Preload(handlers ...PreloadHandler) ModelQuery {
+	query.preloads = append(query.preloads[:len(query.preloads):len(query.preloads)], handlers...)
+	return query
+}
+
+// All retrieves every instance matching the query into given slice.
+func (query ModelQuery) All(ctx context.Context, driver Driver, dest interface{}) error {
+	if !reflectx.IsPointer(dest) || !reflectx.IsSlice(dest) {
+		return errors.Wrapf(ErrPointerRequired, "makroud: cannot execute query on %T", dest)
+	}
+
+	statement, err := query.build(driver)
+	if err != nil {
+		return err
+	}
+
+	err = Exec(ctx, driver, statement, dest)
+	if err != nil {
+		return err
+	}
+
+	return query.preload(ctx, driver, dest)
+}
+
+// One retrieves the first instance matching the query into given model.
+// If no instance is found, an error matching IsErrNoRows is returned.
+func (query ModelQuery) One(ctx context.Context, driver Driver, dest interface{}) error {
+	if !reflectx.IsPointer(dest) || reflectx.IsSlice(dest) {
+		return errors.Wrapf(ErrPointerRequired, "makroud: cannot execute query on %T", dest)
+	}
+
+	if query.limit == nil {
+		query.limit = 1
+	}
+
+	statement, err := query.build(driver)
+	if err != nil {
+		return err
+	}
+
+	err = Exec(ctx, driver, statement, dest)
+	if err != nil {
+		return err
+	}
+
+	return query.preload(ctx, driver, dest)
+}
+
+// Count returns the number of instances matching the query.
+// Projection, grouping, orders, limit and offset are ignored.
+func (query ModelQuery) Count(ctx context.Context, driver Driver) (int64, error) {
+	schema, err := query.schema(driver)
+	if err != nil {
+		return 0, err
+	}
+
+	// Joins on a many association may return the same instance more than once.
+	count := loukoum.Count("*")
+	if len(query.joins) > 0 {
+		count = loukoum.Count(fmt.Sprint("DISTINCT ", schema.PrimaryKeyPath()))
+	}
+
+	statement, err := query.scope(schema, loukoum.Select(count))
+	if err != nil {
+		return 0, err
+	}
+
+	return Count(ctx, driver, statement)
+}
+
+// Builder returns the loukoum builder generated by the query, or an error if it's invalid for given driver.
+func (query ModelQuery) Builder(driver Driver) (builder.Select, error) {
+	return query.build(driver)
+}
+
+func (query ModelQuery) schema(driver Driver) (*Schema, error) {
+	if query.model == nil {
+		return nil, errors.Wrap(ErrModelRequired, "makroud: cannot build query")
+	}
+
+	schema, err := GetSchema(driver, query.model)
+	if err != nil {
+		return nil, errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", query.model)
+	}
+
+	return schema, nil
+}
+
+func (query ModelQuery) build(driver Driver) (builder.Select, error) {
+	schema, err := query.schema(driver)
+	if err != nil {
+		return builder.Select{}, err
+	}
+
+	columns := schema.ColumnPaths().List()
+	if len(query.columns) > 0 {
+		columns = make([]string, 0, len(query.columns))
+		for _, column := range query.columns {
+			if !schema.HasColumn(column) {
+				return builder.Select{}, errors.Wrapf(ErrSchemaColumnRequired,
+					"makroud: cannot use column %s in projection of %T", column, query.model)
+			}
+			if !strings.Contains(column, ".") {
+				column = fmt.Sprint(schema.TableName(), ".", column)
+			}
+			columns = append(columns, column)
+		}
+	}
+
+	statement, err := query.scope(schema, loukoum.Select(columns))
+	if err != nil {
+		return builder.Select{}, err
+	}
+
+	groups, err := query.grouping(schema)
+	if err != nil {
+		return builder.Select{}, err
+	}
+	if len(groups) > 0 {
+		statement = statement.GroupBy(groups)
+	}
+
+	if len(query.orders) == 0 {
+		statement = statement.OrderBy(loukoum.Order(schema.PrimaryKeyPath()))
+	}
+	for _, order := range query.orders {
+		err = query.validate(schema, order.Expression)
+		if err != nil {
+			return builder.Select{}, err
+		}
+		if len(query.groups) == 0 && query.isManyJoinColumn(schema, order.Expression) {
+			return builder.Select{}, errors.Wrapf(ErrSchemaColumnRequired,
+				"makroud: cannot use column %s of a many association in orders of %T", order.Expression, query.model)
+		}
+		order.Expression = query.qualify(schema, order.Expression)
+		statement = statement.OrderBy(order)
+	}
+
+	if query.limit != nil {
+		statement = statement.Limit(query.limit)
+	}
+	if query.offset != nil {
+		statement = statement.Offset(query.offset)
+	}
+
+	return statement, nil
+}
+
+// scope adds the table, joins, conditions and deleted key filters of the query on given builder.
+func (query ModelQuery) scope(schema *Schema, statement builder.Select) (builder.Select, error) {
+	statement = statement.From(schema.TableName())
+
+	if schema.HasDeletedKey() && !query.unscoped {
+		statement = statement.Where(loukoum.Condition(schema.DeletedKeyPath()).IsNull(true))
+	}
+
+	for _, name := range query.joins {
+		reference, ok := schema.associations[name]
+		if !ok {
+			return statement, errors.Wrapf(ErrSchemaInvalidAssociation,
+				"makroud: cannot join '%s' on %T", name, query.model)
+		}
+
+		local := reference.Local()
+		remote := reference.Remote()
+
+		if remote.TableName() == schema.TableName() {
+			return statement, errors.Wrapf(ErrSchemaInvalidAssociation,
+				"makroud: cannot join '%s' on %T, an association on the same table is not supported",
+				name, query.model)
+		}
+
+		on := loukoum.On(local.ColumnPath(), remote.ColumnPath())
+		statement = statement.Join(remote.TableName(), on, loukoum.InnerJoin)
+		if remote.HasDeletedKey() && !query.unscoped {
+			statement = statement.Where(loukoum.Condition(remote.DeletedKeyPath()).IsNull(true))
+		}
+	}
+
+	for _, condition := range query.wheres {
+		for _, column := range getExpressionColumns(condition) {
+			err := query.validate(schema, column)
+			if err != nil {
+				return statement, err
+			}
+		}
+		statement = statement.Where(query.qualifyExpression(schema, condition))
+	}
+
+	return statement, nil
+}
+
+// grouping returns the columns used to group the rows of the query.
+// If the query isn't explicitly grouped, rows are grouped by the model primary key when a many association is
+// joined, so that every instance is returned once.
+func (query ModelQuery) grouping(schema *Schema) ([]string, error) {
+	if len(query.groups) == 0 {
+		if query.hasManyJoin(schema) {
+			return []string{schema.PrimaryKeyPath()}, nil
+		}
+		return nil, nil
+	}
+
+	groups := make([]string, 0, len(query.groups))
+	for _, column := range query.groups {
+		err := query.validate(schema, column)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, query.qualify(schema, column))
+	}
+
+	return groups, nil
+}
+
+// hasManyJoin returns if a many association is joined by the query.
+func (query ModelQuery) hasManyJoin(schema *Schema) bool {
+	for _, name := range query.joins {
+		reference, ok := schema.associations[name]
+		if ok && reference.IsAssociationType(AssociationTypeMany) {
+			return true
+		}
+	}
+	return false
+}
+
+// isManyJoinColumn returns if given column belongs to a joined many association.
+func (query ModelQuery) isManyJoinColumn(schema *Schema, column string) bool {
+	for _, name := range query.joins {
+		reference, ok := schema.associations[name]
+		if !ok || !reference.IsAssociationType(AssociationTypeMany) {
+			continue
+		}
+		if strings.HasPrefix(column, fmt.Sprint(reference.Remote().TableName(), ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// qualify returns given column with the model table, if it's not qualified and associations are joined.
+// Otherwise, a column defined by both the model and a joined association would be ambiguous.
+func (query ModelQuery) qualify(schema *Schema, column string) string {
+	if len(query.joins) == 0 || strings.Contains(column, ".") {
+		return column
+	}
+	return fmt.Sprint(schema.TableName(), ".", column)
+}
+
+// qualifyExpression returns given expression with every column qualified with the model table, if it's not
+// qualified and associations are joined.
+func (query ModelQuery) qualifyExpression(schema *Schema, expression stmt.Expression) stmt.Expression {
+	if len(query.joins) == 0 {
+		return expression
+	}
+
+	switch value := expression.(type) {
+	case stmt.Identifier:
+		value.Identifier = query.qualify(schema, value.Identifier)
+		return value
+	case stmt.InfixExpression:
+		value.Left = query.qualifyExpression(schema, value.Left)
+		value.Right = query.qualifyExpression(schema, value.Right)
+		return value
+	case stmt.In:
+		value.Identifier.Identifier = query.qualify(schema, value.Identifier.Identifier)
+		return value
+	case stmt.Between:
+		value.Identifier.Identifier = query.qualify(schema, value.Identifier.Identifier)
+		return value
+	default:
+		return expression
+	}
+}
+
+// validate returns an error if given column is neither defined by the model schema, nor by the schema of a
+// joined association.
+func (query ModelQuery) validate(schema *Schema, column string) error {
+	if schema.HasColumn(column) {
+		return nil
+	}
+
+	for _, name := range query.joins {
+		reference, ok := schema.associations[name]
+		if !ok {
+			continue
+		}
+
+		remote := reference.Remote()
+		if strings.HasPrefix(column, fmt.Sprint(remote.TableName(), ".")) && remote.Schema().HasColumn(column) {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(ErrSchemaColumnRequired, "makroud: cannot use column %s in query of %T", column, query.model)
+}
+
+func (query ModelQuery) preload(ctx context.Context, driver Driver, dest interface{}) error {
+	if len(query.preloads) == 0 {
+		return nil
+	}
+	return Preload(ctx, driver, dest, query.preloads...)
+}
+
+// getExpressionColumns returns every column used by given loukoum expression.
+// Raw expressions and subqueries are ignored.
+func getExpressionColumns(expression stmt.Expression) []string {
+	switch value := expression.(type) {
+	case stmt.Identifier:
+		return []string{value.Identifier}
+	case stmt.InfixExpression:
+		return append(getExpressionColumns(value.Left), getExpressionColumns(value.Right)...)
+	case stmt.In:
+		return getExpressionColumns(value.Identifier)
+	case stmt.Between:
+		return getExpressionColumns(value.Identifier)
+	default:
+		return nil
+	}
+}
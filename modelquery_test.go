@@ -0,0 +1,150 @@
+package makroud_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+func TestModelQuery(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+		fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+		{
+			owls := []Owl{}
+			query := makroud.From(&Owl{}).
+				Join("Group").
+				Where(loukoum.Condition("ztp_group.name").Equal("Spring")).
+				OrderBy(loukoum.Order("ztp_owl.name", loukoum.Desc))
+
+			err := query.All(ctx, driver, &owls)
+			is.NoError(err)
+			is.Len(owls, 2)
+			is.Equal("Wacky", owls[0].Name)
+			is.Equal("Pyro", owls[1].Name)
+
+			count, err := query.Count(ctx, driver)
+			is.NoError(err)
+			is.Equal(int64(2), count)
+		}
+		{
+			owl := &Owl{}
+			err := makroud.From(&Owl{}).
+				Select("id", "name").
+				Where(loukoum.Condition("name").Equal("Puffins")).
+				Preload(makroud.WithPreloadField("Bag")).
+				One(ctx, driver, owl)
+			is.NoError(err)
+			is.Equal(fixtures.Owls[3].ID, owl.ID)
+			is.Equal("Puffins", owl.Name)
+			is.Empty(owl.FeatherColor)
+			is.NotNil(owl.Bag)
+			is.Equal("Ordinary Maroon", owl.Bag.Color)
+
+			err = makroud.From(&Owl{}).
+				Where(loukoum.Condition("name").Equal("Hedwig")).
+				One(ctx, driver, owl)
+			is.Error(err)
+			is.True(makroud.IsErrNoRows(err))
+		}
+		{
+			owls := []Owl{}
+			err := makroud.From(&Owl{}).Limit(2).Offset(1).All(ctx, driver, &owls)
+			is.NoError(err)
+			is.Len(owls, 2)
+			is.Equal(fixtures.Owls[1].ID, owls[0].ID)
+			is.Equal(fixtures.Owls[2].ID, owls[1].ID)
+		}
+		{
+			cat := fixtures.Cats[0]
+			err := makroud.Archive(ctx, driver, cat)
+			is.NoError(err)
+
+			query := makroud.From(&Cat{}).Where(loukoum.Condition("id").Equal(cat.ID))
+
+			count, err := query.Count(ctx, driver)
+			is.NoError(err)
+			is.Equal(int64(0), count)
+
+			count, err = query.Unscoped().Count(ctx, driver)
+			is.NoError(err)
+			is.Equal(int64(1), count)
+		}
+		{
+			owls := []Owl{}
+
+			err := makroud.From(&Owl{}).
+				Where(loukoum.Condition("color").Equal("Frosty Cyan")).
+				All(ctx, driver, &owls)
+			is.Error(err)
+			is.Equal(makroud.ErrSchemaColumnRequired, errors.Cause(err))
+
+			err = makroud.From(&Owl{}).
+				OrderBy(loukoum.Order("ztp_bag.color")).
+				All(ctx, driver, &owls)
+			is.Error(err)
+			is.Equal(makroud.ErrSchemaColumnRequired, errors.Cause(err))
+
+			err = makroud.From(&Owl{}).
+				Join("Bag").
+				OrderBy(loukoum.Order("ztp_bag.color")).
+				All(ctx, driver, &owls)
+			is.NoError(err)
+			is.Len(owls, 5)
+
+			err = makroud.From(&Owl{}).Join("Nest").All(ctx, driver, &owls)
+			is.Error(err)
+			is.Equal(makroud.ErrSchemaInvalidAssociation, errors.Cause(err))
+		}
+		{
+			owls := []Owl{}
+			query := makroud.From(&Owl{}).
+				Join("Group").
+				Where(loukoum.Condition("name").Equal("Wacky")).
+				OrderBy(loukoum.Order("name"))
+
+			err := query.All(ctx, driver, &owls)
+			is.NoError(err)
+			is.Len(owls, 1)
+			is.Equal("Wacky", owls[0].Name)
+
+			owls = []Owl{}
+			err = query.GroupBy("id").All(ctx, driver, &owls)
+			is.NoError(err)
+			is.Len(owls, 1)
+
+			err = query.GroupBy("ztp_owl.unknown").All(ctx, driver, &owls)
+			is.Error(err)
+			is.Equal(makroud.ErrSchemaColumnRequired, errors.Cause(err))
+		}
+		{
+			cats := []Cat{}
+			query := makroud.From(&Cat{}).Join("Meows")
+
+			err := query.All(ctx, driver, &cats)
+			is.NoError(err)
+			is.Len(cats, 5)
+
+			ids := map[string]bool{}
+			for _, cat := range cats {
+				is.False(ids[cat.ID])
+				ids[cat.ID] = true
+			}
+
+			count, err := query.Count(ctx, driver)
+			is.NoError(err)
+			is.Equal(int64(len(cats)), count)
+
+			err = query.OrderBy(loukoum.Order("ztp_meow.body")).All(ctx, driver, &cats)
+			is.Error(err)
+			is.Equal(makroud.ErrSchemaColumnRequired, errors.Cause(err))
+		}
+	})
+}
@@ -3,6 +3,7 @@ package makroud
 import (
 	"context"
 	"database/sql"
+	sqldriver "database/sql/driver"
 	"strings"
 	"time"
 
@@ -55,12 +56,17 @@ type Node interface {
 	// SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
 	// Expired connections may be closed lazily before reuse.
 	SetConnMaxLifetime(duration time.Duration)
+	// SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
+	// Expired connections may be closed lazily before reuse.
+	SetConnMaxIdleTime(duration time.Duration)
 	// SetMaxIdleConns sets the maximum number of connections in the idle connection pool.
 	SetMaxIdleConns(number int)
 	// SetMaxOpenConns sets the maximum number of open connections to the database.
 	SetMaxOpenConns(number int)
 	// EnableSavepoint activate PostgreSQL savepoints for nested transactions.
 	EnableSavepoint(enabled bool)
+	// SetDialect sets the SQL dialect used to create, release and rollback savepoints.
+	SetDialect(dialect Dialect)
 	// Stats returns database statistics.
 	Stats() sql.DBStats
 
@@ -85,6 +91,13 @@ type Node interface {
 	Rollback() error
 	// Commit commits the associated transaction.
 	Commit() error
+	// IsNested returns if the node is a nested transaction, using either a savepoint or its parent transaction.
+	IsNested() bool
+	// NestingDepth returns the number of transactions the node is bound to: zero outside of a transaction, one
+	// in a transaction and more than one in a nested transaction.
+	NestingDepth() int
+	// SavepointName returns the savepoint name of this nested transaction, if savepoints are enabled.
+	SavepointName() string
 
 	// ----------------------------------------------------------------------------
 	// System
@@ -103,6 +116,8 @@ type node struct {
 	savePointID      string
 	savePointEnabled bool
 	nested           bool
+	depth            int
+	dialect          Dialect
 }
 
 // Connect connects to a database and verifies connection with a ping.
@@ -128,6 +143,27 @@ func Connect(driver string, dsn string) (Node, error) {
 	return node, nil
 }
 
+// ConnectWithConnector connects to a database using given driver connector and verifies the connection.
+// The driver name is only used as an informative value.
+func ConnectWithConnector(driver string, connector sqldriver.Connector) (Node, error) {
+	db := sql.OpenDB(connector)
+
+	err := db.Ping()
+	if err != nil {
+		// the connection has been opened within this function, we must close it
+		// on error.
+		_ = db.Close()
+		return nil, err
+	}
+
+	node := &node{
+		driver: driver,
+		db:     db,
+	}
+
+	return node, nil
+}
+
 // NewNode returns a new Node.
 func NewNode(db *sql.DB) Node {
 	return &node{db: db}
@@ -181,15 +217,17 @@ func (node *node) BeginTx(ctx context.Context, opts *sql.TxOptions) (Node, error
 		}
 
 		clone.tx = tx
+		clone.depth = 1
 
 	case clone.savePointEnabled:
 
 		// Already in a transaction: using savepoints
 		clone.nested = true
+		clone.depth++
 
 		// Savepoints name must start with a char and cannot contain dashes (-)
 		clone.savePointID = "sp_" + strings.Replace(uuid.Must(uuid.NewV1()).String(), "-", "_", -1)
-		_, err := node.tx.Exec("SAVEPOINT " + clone.savePointID)
+		_, err := node.tx.Exec(node.getDialect().Savepoint(clone.savePointID))
 		if err != nil {
 			return nil, err
 		}
@@ -198,6 +236,7 @@ func (node *node) BeginTx(ctx context.Context, opts *sql.TxOptions) (Node, error
 
 		// Already in a transaction: reusing current one.
 		clone.nested = true
+		clone.depth++
 	}
 
 	return clone, nil
@@ -211,7 +250,7 @@ func (node *node) Commit() (err error) {
 
 	if node.savePointID != "" {
 
-		_, err = node.tx.Exec("RELEASE SAVEPOINT " + node.savePointID)
+		_, err = node.tx.Exec(node.getDialect().ReleaseSavepoint(node.savePointID))
 		if err != nil {
 			return err
 		}
@@ -224,6 +263,7 @@ func (node *node) Commit() (err error) {
 	}
 
 	node.tx = nil
+	node.depth = 0
 
 	return nil
 }
@@ -236,7 +276,7 @@ func (node *node) Rollback() (err error) {
 
 	if node.savePointEnabled && node.savePointID != "" {
 
-		_, err = node.tx.Exec("ROLLBACK TO SAVEPOINT " + node.savePointID)
+		_, err = node.tx.Exec(node.getDialect().RollbackToSavepoint(node.savePointID))
 		if err != nil {
 			return err
 		}
@@ -251,10 +291,27 @@ func (node *node) Rollback() (err error) {
 	}
 
 	node.tx = nil
+	node.depth = 0
 
 	return nil
 }
 
+// IsNested returns if the node is a nested transaction, using either a savepoint or its parent transaction.
+func (node *node) IsNested() bool {
+	return node.nested
+}
+
+// NestingDepth returns the number of transactions the node is bound to: zero outside of a transaction, one
+// in a transaction and more than one in a nested transaction.
+func (node *node) NestingDepth() int {
+	return node.depth
+}
+
+// SavepointName returns the savepoint name of this nested transaction, if savepoints are enabled.
+func (node *node) SavepointName() string {
+	return node.savePointID
+}
+
 // Tx returns the underlying transaction.
 func (node *node) Tx() *sql.Tx {
 	return node.tx
@@ -337,6 +394,12 @@ func (node *node) SetConnMaxLifetime(duration time.Duration) {
 	node.db.SetConnMaxLifetime(duration)
 }
 
+// SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
+// Expired connections may be closed lazily before reuse.
+func (node *node) SetConnMaxIdleTime(duration time.Duration) {
+	node.db.SetConnMaxIdleTime(duration)
+}
+
 // SetMaxIdleConns sets the maximum number of connections in the idle connection pool.
 func (node *node) SetMaxIdleConns(number int) {
 	node.db.SetMaxIdleConns(number)
@@ -352,6 +415,19 @@ func (node *node) EnableSavepoint(enabled bool) {
 	node.savePointEnabled = enabled
 }
 
+// SetDialect sets the SQL dialect used to create, release and rollback savepoints.
+func (node *node) SetDialect(dialect Dialect) {
+	node.dialect = dialect
+}
+
+// getDialect returns the SQL dialect of this node, PostgreSQL being used by default.
+func (node *node) getDialect() Dialect {
+	if node.dialect == nil {
+		return PostgresDialect{}
+	}
+	return node.dialect
+}
+
 // Stats returns database statistics.
 func (node *node) Stats() sql.DBStats {
 	return node.db.Stats()
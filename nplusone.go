@@ -0,0 +1,252 @@
+package makroud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NPlusOneObserver is an Observer that also gathers N+1 queries detected within a query scope.
+type NPlusOneObserver interface {
+	Observer
+	// OnNPlusOne
+	OnNPlusOne(ctx context.Context, detection NPlusOne)
+}
+
+// NPlusOne describes a query shape executed repeatedly within a query scope, where only arguments differ.
+// For example, the same "SELECT ... FROM comments WHERE post_id = $1" executed for each post of a list.
+type NPlusOne struct {
+	// Query is the query shape, with its placeholders normalized.
+	Query string
+	// Table is the table name queried by this shape.
+	Table string
+	// Count is how many times this shape was executed within the scope.
+	Count int
+	// Callers contains the call sites (outside of makroud) that executed this shape.
+	Callers []string
+	// Suggestions contains the relations that could be preloaded instead, using WithPreloadField.
+	// For example: "Post: WithPreloadField(\"Comments\")"
+	Suggestions []string
+}
+
+type queryScopeKey struct{}
+
+// QueryScope records query shapes executed within a context, such as an HTTP request,
+// in order to detect N+1 queries.
+type QueryScope struct {
+	mutex      sync.Mutex
+	shapes     map[string]*NPlusOne
+	detections []*NPlusOne
+}
+
+// NewQueryScope returns a new context with a query scope attached.
+// Queries executed with this context, or a derived one, will be recorded by the scope if the driver has N+1
+// detection enabled.
+func NewQueryScope(ctx context.Context) (context.Context, *QueryScope) {
+	scope := &QueryScope{
+		shapes: map[string]*NPlusOne{},
+	}
+	return context.WithValue(ctx, queryScopeKey{}, scope), scope
+}
+
+// GetQueryScope returns the query scope attached to given context, or nil.
+func GetQueryScope(ctx context.Context) *QueryScope {
+	if ctx == nil {
+		return nil
+	}
+	scope, ok := ctx.Value(queryScopeKey{}).(*QueryScope)
+	if !ok {
+		return nil
+	}
+	return scope
+}
+
+// Detections returns N+1 queries detected within this scope.
+func (scope *QueryScope) Detections() []NPlusOne {
+	scope.mutex.Lock()
+	defer scope.mutex.Unlock()
+
+	list := make([]NPlusOne, 0, len(scope.detections))
+	for i := range scope.detections {
+		detection := *scope.detections[i]
+		detection.Callers = append([]string{}, detection.Callers...)
+		detection.Suggestions = append([]string{}, detection.Suggestions...)
+		list = append(list, detection)
+	}
+
+	return list
+}
+
+// record adds given query shape to the scope, and returns a detection if given threshold has just been reached.
+func (scope *QueryScope) record(shape string, caller string, threshold int) (*NPlusOne, bool) {
+	scope.mutex.Lock()
+	defer scope.mutex.Unlock()
+
+	entry, ok := scope.shapes[shape]
+	if !ok {
+		entry = &NPlusOne{
+			Query:   shape,
+			Callers: []string{},
+		}
+		scope.shapes[shape] = entry
+	}
+
+	entry.Count++
+	if caller != "" && !hasString(entry.Callers, caller) && len(entry.Callers) < maxNPlusOneCallers {
+		entry.Callers = append(entry.Callers, caller)
+	}
+
+	if entry.Count != threshold {
+		return nil, false
+	}
+
+	scope.detections = append(scope.detections, entry)
+	detection := *entry
+	detection.Callers = append([]string{}, entry.Callers...)
+
+	return &detection, true
+}
+
+// maxNPlusOneCallers defines how many call sites are kept for a query shape.
+const maxNPlusOneCallers = 5
+
+var (
+	nPlusOneSelect   = regexp.MustCompile(`(?i)^\s*SELECT\b`)
+	nPlusOneTable    = regexp.MustCompile(`(?i)\bFROM\s+(\w+)`)
+	nPlusOnePackage  = reflect.TypeOf(Client{}).PkgPath()
+	nPlusOnePatterns = sync.Map{}
+)
+
+// nPlusOnePattern contains the regular expressions used to normalize the queries of a dialect.
+type nPlusOnePattern struct {
+	placeholders *regexp.Regexp
+	columns      *regexp.Regexp
+}
+
+// getNPlusOnePattern returns the regular expressions matching the bind parameters of given dialect,
+// such as "$1" for PostgreSQL or "?1" for SQLite.
+func getNPlusOnePattern(dialect Dialect) *nPlusOnePattern {
+	first := dialect.Placeholder(1)
+
+	pattern, ok := nPlusOnePatterns.Load(first)
+	if ok {
+		return pattern.(*nPlusOnePattern)
+	}
+
+	prefix := strings.TrimRight(first, "0123456789")
+	placeholder := regexp.QuoteMeta(prefix)
+	if prefix != first {
+		placeholder = fmt.Sprint(placeholder, `\d+`)
+	}
+
+	pattern, _ = nPlusOnePatterns.LoadOrStore(first, &nPlusOnePattern{
+		placeholders: regexp.MustCompile(fmt.Sprint(placeholder, `(\s*,\s*`, placeholder, `)*`)),
+		columns:      regexp.MustCompile(fmt.Sprint(`(?i)(?:\w+\.)?(\w+)\s*(?:=|\bIN)\s*\(?\s*`, placeholder)),
+	})
+
+	return pattern.(*nPlusOnePattern)
+}
+
+// recordQueryShape records given query in the context query scope, if any, and notifies the driver's observer
+// when this query shape has been executed too many times.
+// Only SELECT statements are recorded: a mutation executed in a loop can't be replaced by a preload.
+func recordQueryShape(ctx context.Context, driver Driver, query Query) {
+	threshold := driver.NPlusOneThreshold()
+	if threshold <= 0 || len(query.Args) == 0 || !nPlusOneSelect.MatchString(query.Query) {
+		return
+	}
+
+	scope := GetQueryScope(ctx)
+	if scope == nil {
+		return
+	}
+
+	pattern := getNPlusOnePattern(getDialect(driver))
+	shape := pattern.placeholders.ReplaceAllString(query.Query, "?")
+
+	detection, ok := scope.record(shape, getNPlusOneCaller(), threshold)
+	if !ok {
+		return
+	}
+
+	match := nPlusOneTable.FindStringSubmatch(query.Query)
+	if len(match) > 1 {
+		detection.Table = match[1]
+		detection.Suggestions = getNPlusOneSuggestions(driver, pattern, detection.Table, query.Query)
+		scope.mutex.Lock()
+		scope.shapes[shape].Table = detection.Table
+		scope.shapes[shape].Suggestions = detection.Suggestions
+		scope.mutex.Unlock()
+	}
+
+	if !driver.HasObserver() {
+		return
+	}
+
+	observer, ok := driver.Observer().(NPlusOneObserver)
+	if ok {
+		observer.OnNPlusOne(ctx, *detection)
+	}
+}
+
+// getNPlusOneCaller returns the first call site outside of makroud.
+func getNPlusOneCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !isMakroudFrame(frame.Function) {
+			return fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func isMakroudFrame(function string) bool {
+	return strings.HasPrefix(function, fmt.Sprint(nPlusOnePackage, ".")) ||
+		strings.HasPrefix(function, fmt.Sprint(nPlusOnePackage, "/"))
+}
+
+// getNPlusOneSuggestions returns a list of associations, from the schemas cached by the driver, that could be
+// preloaded to fetch the given table in a single query.
+func getNPlusOneSuggestions(driver Driver, pattern *nPlusOnePattern, table string, query string) []string {
+	if !driver.HasCache() {
+		return []string{}
+	}
+
+	columns := map[string]bool{}
+	for _, match := range pattern.columns.FindAllStringSubmatch(query, -1) {
+		columns[match[1]] = true
+	}
+
+	suggestions := []string{}
+	driver.GetCache().forEachSchema(func(schema *Schema) {
+		for name, association := range schema.associations {
+			remote := association.Remote()
+			if remote.TableName() == table && columns[remote.ColumnName()] {
+				suggestions = append(suggestions, fmt.Sprintf("%s: WithPreloadField(%q)", schema.ModelName(), name))
+			}
+		}
+	})
+
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+func hasString(list []string, value string) bool {
+	for i := range list {
+		if list[i] == value {
+			return true
+		}
+	}
+	return false
+}
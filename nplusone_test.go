@@ -0,0 +1,124 @@
+package makroud_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+type nPlusOneObserver struct {
+	detections []makroud.NPlusOne
+}
+
+func (e *nPlusOneObserver) OnClose(err error, flags map[string]string) {}
+
+func (e *nPlusOneObserver) OnRollback(err error, flags map[string]string) {}
+
+func (e *nPlusOneObserver) OnNPlusOne(ctx context.Context, detection makroud.NPlusOne) {
+	e.detections = append(e.detections, detection)
+}
+
+func TestNPlusOne(t *testing.T) {
+	observer := &nPlusOneObserver{}
+	Setup(t, makroud.WithObserver(observer), makroud.DetectNPlusOne(3))(func(driver makroud.Driver) {
+		is := require.New(t)
+		ctx, scope := makroud.NewQueryScope(context.Background())
+
+		GenerateExoCloudFixtures(context.Background(), driver, is)
+
+		users := []ExoUser{}
+		err := makroud.Select(ctx, driver, &users)
+		is.NoError(err)
+		is.True(len(users) > 3)
+
+		for i := range users {
+			profile := &ExoProfile{}
+			err = makroud.Select(ctx, driver, profile, loukoum.Condition("id").Equal(users[i].ProfileID))
+			is.NoError(err)
+		}
+
+		is.Len(observer.detections, 1)
+		detection := observer.detections[0]
+		is.Equal("exo_profile", detection.Table)
+		is.Equal(3, detection.Count)
+		is.Contains(detection.Query, "WHERE (id = ?)")
+		is.Len(detection.Callers, 1)
+		is.Contains(detection.Callers[0], "nplusone_test.go")
+		is.Contains(detection.Suggestions, `ExoUser: WithPreloadField("Profile")`)
+
+		detections := scope.Detections()
+		is.Len(detections, 1)
+		is.Equal(len(users), detections[0].Count)
+		is.Equal(detection.Suggestions, detections[0].Suggestions)
+	})
+}
+
+func TestNPlusOne_WithoutScope(t *testing.T) {
+	observer := &nPlusOneObserver{}
+	Setup(t, makroud.WithObserver(observer), makroud.DetectNPlusOne(2))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		GenerateExoCloudFixtures(ctx, driver, is)
+
+		users := []ExoUser{}
+		err := makroud.Select(ctx, driver, &users)
+		is.NoError(err)
+
+		for i := range users {
+			profile := &ExoProfile{}
+			err = makroud.Select(ctx, driver, profile, loukoum.Condition("id").Equal(users[i].ProfileID))
+			is.NoError(err)
+		}
+
+		is.Empty(observer.detections)
+	})
+}
+
+func TestNPlusOne_WithPreload(t *testing.T) {
+	observer := &nPlusOneObserver{}
+	Setup(t, makroud.WithObserver(observer), makroud.DetectNPlusOne(2))(func(driver makroud.Driver) {
+		is := require.New(t)
+		ctx, scope := makroud.NewQueryScope(context.Background())
+
+		GenerateExoCloudFixtures(context.Background(), driver, is)
+
+		users := []ExoUser{}
+		err := makroud.Select(ctx, driver, &users)
+		is.NoError(err)
+
+		err = makroud.Preload(ctx, driver, &users, makroud.WithPreloadField("Profile"))
+		is.NoError(err)
+
+		is.Empty(observer.detections)
+		is.Empty(scope.Detections())
+	})
+}
+
+func TestNPlusOne_WithMutations(t *testing.T) {
+	observer := &nPlusOneObserver{}
+	Setup(t, makroud.WithObserver(observer), makroud.DetectNPlusOne(2))(func(driver makroud.Driver) {
+		is := require.New(t)
+		ctx, scope := makroud.NewQueryScope(context.Background())
+
+		GenerateExoCloudFixtures(context.Background(), driver, is)
+
+		profiles := []ExoProfile{}
+		err := makroud.Select(ctx, driver, &profiles)
+		is.NoError(err)
+		is.True(len(profiles) > 2)
+
+		for i := range profiles {
+			profiles[i].LastName = "Doe"
+			err = makroud.Save(ctx, driver, &profiles[i])
+			is.NoError(err)
+		}
+
+		is.Empty(observer.detections)
+		is.Empty(scope.Detections())
+	})
+}
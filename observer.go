@@ -1,5 +1,17 @@
 package makroud
 
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 // Observer is a collector that gathers various runtime error.
 type Observer interface {
 	// OnClose
@@ -7,3 +19,186 @@ type Observer interface {
 	// OnRollback
 	OnRollback(err error, flags map[string]string)
 }
+
+// SlowQueryObserver is an Observer that also gathers queries exceeding the slow query threshold.
+type SlowQueryObserver interface {
+	Observer
+	// OnSlowQuery
+	OnSlowQuery(ctx context.Context, query SlowQuery)
+}
+
+// SlowQuery describes a query that exceeded the slow query threshold of a driver.
+type SlowQuery struct {
+	// Query is the executed query.
+	Query Query
+	// Duration is how long the query took to execute.
+	Duration time.Duration
+	// Threshold is the slow query threshold configured on the driver.
+	Threshold time.Duration
+	// Plan contains the execution plan in JSON format, if explain is enabled.
+	Plan []byte
+	// Err is the error returned while trying to obtain the execution plan, if any.
+	Err error
+}
+
+// TransactionObserver is an Observer that also gathers transaction lifecycle events.
+// The given error is nil if the operation has succeeded.
+type TransactionObserver interface {
+	Observer
+	// OnBegin
+	OnBegin(err error, flags map[string]string)
+	// OnCommit
+	OnCommit(err error, flags map[string]string)
+	// OnTransactionRollback
+	OnTransactionRollback(err error, flags map[string]string)
+}
+
+// SavepointObserver is an Observer that also gathers savepoint lifecycle events of nested transactions.
+// The given error is nil if the operation has succeeded.
+type SavepointObserver interface {
+	Observer
+	// OnSavepointCreate
+	OnSavepointCreate(err error, flags map[string]string)
+	// OnSavepointRelease
+	OnSavepointRelease(err error, flags map[string]string)
+	// OnSavepointRollback
+	OnSavepointRollback(err error, flags map[string]string)
+}
+
+// QueryObserver is an Observer that also gathers query and scan errors.
+type QueryObserver interface {
+	Observer
+	// OnQueryError
+	OnQueryError(err error, flags map[string]string)
+	// OnScanError
+	OnScanError(err error, flags map[string]string)
+}
+
+// ConnectionObserver is an Observer that also gathers connection lifecycle events.
+type ConnectionObserver interface {
+	Observer
+	// OnReconnect is called when the database is reachable again, with the error that caused the
+	// connection loss.
+	OnReconnect(err error, flags map[string]string)
+}
+
+func notifyBegin(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(TransactionObserver)
+	if ok {
+		handler.OnBegin(err, flags)
+	}
+}
+
+func notifyCommit(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(TransactionObserver)
+	if ok {
+		handler.OnCommit(err, flags)
+	}
+}
+
+func notifyTransactionRollback(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(TransactionObserver)
+	if ok {
+		handler.OnTransactionRollback(err, flags)
+	}
+}
+
+func notifySavepointCreate(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(SavepointObserver)
+	if ok {
+		handler.OnSavepointCreate(err, flags)
+	}
+}
+
+func notifySavepointRelease(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(SavepointObserver)
+	if ok {
+		handler.OnSavepointRelease(err, flags)
+	}
+}
+
+func notifySavepointRollback(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(SavepointObserver)
+	if ok {
+		handler.OnSavepointRollback(err, flags)
+	}
+}
+
+func notifyQueryError(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(QueryObserver)
+	if ok {
+		handler.OnQueryError(err, flags)
+	}
+}
+
+func notifyScanError(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(QueryObserver)
+	if ok {
+		handler.OnScanError(err, flags)
+	}
+}
+
+func notifyReconnect(observer Observer, err error, flags map[string]string) {
+	handler, ok := observer.(ConnectionObserver)
+	if ok {
+		handler.OnReconnect(err, flags)
+	}
+}
+
+// connectionState is shared between a Client and its transactions to detect when the database is reachable
+// again after a connection failure.
+type connectionState struct {
+	lost  int32
+	mutex sync.Mutex
+	cause error
+}
+
+// fail records given error if it's a connection failure.
+func (state *connectionState) fail(err error) {
+	if !isConnectionError(err) {
+		return
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	if state.cause == nil {
+		state.cause = err
+		atomic.StoreInt32(&state.lost, 1)
+	}
+}
+
+// restore returns if the connection was lost, and the error that caused the connection loss.
+func (state *connectionState) restore() (bool, error) {
+	if atomic.LoadInt32(&state.lost) == 0 {
+		return false, nil
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	cause := state.cause
+	if cause == nil {
+		return false, nil
+	}
+
+	state.cause = nil
+	atomic.StoreInt32(&state.lost, 0)
+
+	return true, cause
+}
+
+// isConnectionError returns if given error is caused by a broken connection to the database.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	err = errors.Cause(err)
+	if err == driver.ErrBadConn || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+
+	_, ok := err.(net.Error)
+	return ok
+}
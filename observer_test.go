@@ -0,0 +1,262 @@
+package makroud_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/makroud"
+)
+
+type observerEvent struct {
+	name  string
+	err   error
+	flags map[string]string
+}
+
+type eventObserver struct {
+	events []observerEvent
+}
+
+func (e *eventObserver) push(name string, err error, flags map[string]string) {
+	e.events = append(e.events, observerEvent{name: name, err: err, flags: flags})
+}
+
+func (e *eventObserver) names() []string {
+	names := make([]string, 0, len(e.events))
+	for i := range e.events {
+		names = append(names, e.events[i].name)
+	}
+	return names
+}
+
+func (e *eventObserver) find(name string) (observerEvent, bool) {
+	for i := range e.events {
+		if e.events[i].name == name {
+			return e.events[i], true
+		}
+	}
+	return observerEvent{}, false
+}
+
+func (e *eventObserver) OnClose(err error, flags map[string]string) {
+	e.push("close", err, flags)
+}
+
+func (e *eventObserver) OnRollback(err error, flags map[string]string) {
+	e.push("rollback", err, flags)
+}
+
+func (e *eventObserver) OnBegin(err error, flags map[string]string) {
+	e.push("begin", err, flags)
+}
+
+func (e *eventObserver) OnCommit(err error, flags map[string]string) {
+	e.push("commit", err, flags)
+}
+
+func (e *eventObserver) OnTransactionRollback(err error, flags map[string]string) {
+	e.push("transaction-rollback", err, flags)
+}
+
+func (e *eventObserver) OnSavepointCreate(err error, flags map[string]string) {
+	e.push("savepoint-create", err, flags)
+}
+
+func (e *eventObserver) OnSavepointRelease(err error, flags map[string]string) {
+	e.push("savepoint-release", err, flags)
+}
+
+func (e *eventObserver) OnSavepointRollback(err error, flags map[string]string) {
+	e.push("savepoint-rollback", err, flags)
+}
+
+func (e *eventObserver) OnQueryError(err error, flags map[string]string) {
+	e.push("query-error", err, flags)
+}
+
+func (e *eventObserver) OnScanError(err error, flags map[string]string) {
+	e.push("scan-error", err, flags)
+}
+
+func (e *eventObserver) OnReconnect(err error, flags map[string]string) {
+	e.push("reconnect", err, flags)
+}
+
+func TestObserver_Transaction(t *testing.T) {
+	observer := &eventObserver{}
+	Setup(t, makroud.WithObserver(observer))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		observer.events = nil
+
+		err := makroud.Transaction(ctx, driver, nil, func(tx makroud.Driver) error {
+			is.True(tx.HasObserver())
+			return makroud.Save(ctx, tx, &Cat{Name: "Pixel"})
+		})
+		is.NoError(err)
+		is.Equal([]string{"begin", "commit"}, observer.names())
+
+		for i := range observer.events {
+			is.NoError(observer.events[i].err)
+			is.Equal("false", observer.events[i].flags["nested"])
+		}
+
+		observer.events = nil
+		failure := fmt.Errorf("transaction failure")
+
+		err = makroud.Transaction(ctx, driver, nil, func(tx makroud.Driver) error {
+			return failure
+		})
+		is.Equal(failure, err)
+		is.Equal([]string{"begin", "transaction-rollback"}, observer.names())
+
+		rollback, ok := observer.find("transaction-rollback")
+		is.True(ok)
+		is.NoError(rollback.err)
+		is.Equal("rollback", rollback.flags["action"])
+		is.Equal("false", rollback.flags["nested"])
+	})
+}
+
+func TestObserver_Savepoint(t *testing.T) {
+	observer := &eventObserver{}
+	Setup(t, makroud.WithObserver(observer), makroud.EnableSavepoint())(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+		failure := fmt.Errorf("nested failure")
+
+		observer.events = nil
+
+		err := makroud.Transaction(ctx, driver, nil, func(tx1 makroud.Driver) error {
+			err := makroud.Transaction(ctx, tx1, nil, func(tx2 makroud.Driver) error {
+				return makroud.Save(ctx, tx2, &Cat{Name: "Tigger"})
+			})
+			is.NoError(err)
+
+			err = makroud.Transaction(ctx, tx1, nil, func(tx2 makroud.Driver) error {
+				return failure
+			})
+			is.Equal(failure, err)
+
+			return nil
+		})
+		is.NoError(err)
+		is.Equal([]string{
+			"begin",
+			"savepoint-create", "savepoint-release",
+			"savepoint-create", "savepoint-rollback",
+			"commit",
+		}, observer.names())
+
+		create, ok := observer.find("savepoint-create")
+		is.True(ok)
+		is.NoError(create.err)
+		is.NotEmpty(create.flags["savepoint"])
+
+		release, ok := observer.find("savepoint-release")
+		is.True(ok)
+		is.Equal(create.flags["savepoint"], release.flags["savepoint"])
+	})
+}
+
+func TestObserver_QueryError(t *testing.T) {
+	observer := &eventObserver{}
+	Setup(t, makroud.WithObserver(observer))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		observer.events = nil
+
+		query := `SELECT name FROM ztp_unknown_table`
+		err := driver.Exec(ctx, query)
+		is.Error(err)
+
+		event, ok := observer.find("query-error")
+		is.True(ok)
+		is.Error(event.err)
+		is.Equal(query, event.flags["query"])
+		is.Equal("exec", event.flags["action"])
+	})
+}
+
+func TestObserver_ScanError(t *testing.T) {
+	observer := &eventObserver{}
+	Setup(t, makroud.WithObserver(observer))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		observer.events = nil
+
+		query := `SELECT 'not-a-number'`
+		row, err := driver.QueryRow(ctx, query)
+		is.NoError(err)
+
+		value := int64(0)
+		err = row.Scan(&value)
+		is.Error(err)
+
+		event, ok := observer.find("scan-error")
+		is.True(ok)
+		is.Error(event.err)
+		is.Equal(query, event.flags["query"])
+
+		row, err = driver.QueryRow(ctx, `SELECT 1 WHERE false`)
+		is.NoError(err)
+
+		err = row.Scan(&value)
+		is.True(makroud.IsErrNoRows(err))
+		is.Len(observer.events, 1)
+	})
+}
+
+func TestObserver_Reconnect(t *testing.T) {
+	observer := &eventObserver{}
+	connector := &flakyConnector{pattern: "ztp_owl"}
+	factory := func(dsn string) (driver.Connector, error) {
+		base, err := pq.NewConnector(dsn)
+		connector.Connector = base
+		return connector, err
+	}
+
+	Setup(t, makroud.WithConnector(factory), makroud.WithObserver(observer))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		GenerateZootopiaFixtures(ctx, driver, is)
+
+		observer.events = nil
+
+		atomic.StoreInt32(&connector.failures, 1)
+		owls := []Owl{}
+		err := makroud.Select(ctx, driver, &owls)
+		is.Error(err)
+
+		_, ok := observer.find("reconnect")
+		is.False(ok)
+
+		owls = []Owl{}
+		err = makroud.Select(ctx, driver, &owls)
+		is.NoError(err)
+		is.NotEmpty(owls)
+
+		event, ok := observer.find("reconnect")
+		is.True(ok)
+		is.Error(event.err)
+		is.Equal("query", event.flags["action"])
+
+		// The database is reachable: no other event should be emitted.
+		observer.events = nil
+		err = makroud.Select(ctx, driver, &owls)
+		is.NoError(err)
+
+		_, ok = observer.find("reconnect")
+		is.False(ok)
+	})
+}
@@ -3,9 +3,15 @@ package makroud
 import (
 	"fmt"
 	"io"
+	"net"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+
+	"github.com/ulule/makroud/hooks"
 )
 
 // ClientOptions configure a Client instance.
@@ -16,9 +22,18 @@ type ClientOptions struct {
 	Password           string
 	Database           string
 	SSLMode            string
+	SSLRootCert        string
+	SSLCert            string
+	SSLKey             string
 	Timezone           string
+	SearchPath         []string
+	StatementTimeout   time.Duration
+	LockTimeout        time.Duration
+	IdleInTxTimeout    time.Duration
 	MaxOpenConnections int
 	MaxIdleConnections int
+	ConnMaxLifetime    time.Duration
+	ConnMaxIdleTime    time.Duration
 	WithCache          bool
 	SavepointEnabled   bool
 	ApplicationName    string
@@ -27,28 +42,68 @@ type ClientOptions struct {
 	Observer           Observer
 	Entropy            io.Reader
 	Node               Node
+	SlowQueryThreshold time.Duration
+	SlowQueryExplain   bool
+	NPlusOneThreshold  int
+	Hooks              []hooks.Hooks
+	InitStatements     []string
+	OnConnect          ConnectCallback
+	Credentials        CredentialsProvider
+	DriverName         string
+	Connector          ConnectorFactory
+	Dialect            Dialect
+	QueryTimeout       time.Duration
+	TransactionTimeout time.Duration
+	RetryPolicy        RetryPolicy
+	PreloadBatchSize   int
 }
 
 func (e ClientOptions) String() string {
-	uri := fmt.Sprintf("%s://%s:%s@%s:%d/%s?sslmode=%s&timezone=%s",
+	uri := fmt.Sprintf("%s://%s:%s@%s/%s?sslmode=%s&timezone=%s",
 		ClientDriver,
 		url.QueryEscape(e.User),
 		url.QueryEscape(e.Password),
-		e.Host,
-		e.Port,
-		e.Database,
-		e.SSLMode,
-		e.Timezone,
+		net.JoinHostPort(e.Host, strconv.Itoa(e.Port)),
+		url.PathEscape(e.Database),
+		url.QueryEscape(e.SSLMode),
+		url.QueryEscape(e.Timezone),
 	)
 	if e.ApplicationName != "" {
-		uri = fmt.Sprintf("%s&application_name=%s", uri, e.ApplicationName)
+		uri = fmt.Sprintf("%s&application_name=%s", uri, url.QueryEscape(e.ApplicationName))
 	}
 	if e.ConnectTimeout > 0 {
 		uri = fmt.Sprintf("%s&connect_timeout=%d", uri, e.ConnectTimeout)
 	}
+	if e.SSLRootCert != "" {
+		uri = fmt.Sprintf("%s&sslrootcert=%s", uri, url.QueryEscape(e.SSLRootCert))
+	}
+	if e.SSLCert != "" {
+		uri = fmt.Sprintf("%s&sslcert=%s", uri, url.QueryEscape(e.SSLCert))
+	}
+	if e.SSLKey != "" {
+		uri = fmt.Sprintf("%s&sslkey=%s", uri, url.QueryEscape(e.SSLKey))
+	}
+	if len(e.SearchPath) > 0 {
+		uri = fmt.Sprintf("%s&search_path=%s", uri, url.QueryEscape(strings.Join(e.SearchPath, ",")))
+	}
+	if e.StatementTimeout > 0 {
+		uri = fmt.Sprintf("%s&statement_timeout=%d", uri, durationMilliseconds(e.StatementTimeout))
+	}
+	if e.LockTimeout > 0 {
+		uri = fmt.Sprintf("%s&lock_timeout=%d", uri, durationMilliseconds(e.LockTimeout))
+	}
+	if e.IdleInTxTimeout > 0 {
+		uri = fmt.Sprintf("%s&idle_in_transaction_session_timeout=%d", uri, durationMilliseconds(e.IdleInTxTimeout))
+	}
 	return uri
 }
 
+// durationMilliseconds returns given duration in milliseconds, rounded up so that a positive duration is never
+// converted to zero, which would disable the timeout.
+func durationMilliseconds(duration time.Duration) int64 {
+	return int64((duration + time.Millisecond - 1) / time.Millisecond)
+}
+
 // NewClientOptions creates a new ClientOptions instance with default options.
 func NewClientOptions() *ClientOptions {
 	return &ClientOptions{
@@ -58,9 +113,18 @@ func NewClientOptions() *ClientOptions {
 		Password:           "",
 		Database:           "postgres",
 		SSLMode:            "disable",
+		SSLRootCert:        "",
+		SSLCert:            "",
+		SSLKey:             "",
 		Timezone:           "UTC",
+		SearchPath:         nil,
+		StatementTimeout:   0,
+		LockTimeout:        0,
+		IdleInTxTimeout:    0,
 		MaxOpenConnections: 5,
 		MaxIdleConnections: 2,
+		ConnMaxLifetime:    0,
+		ConnMaxIdleTime:    0,
 		WithCache:          true,
 		SavepointEnabled:   false,
 		ApplicationName:    "Makroud",
@@ -69,6 +133,20 @@ func NewClientOptions() *ClientOptions {
 		Observer:           nil,
 		Entropy:            nil,
 		Node:               nil,
+		SlowQueryThreshold: 0,
+		SlowQueryExplain:   false,
+		NPlusOneThreshold:  0,
+		Hooks:              nil,
+		InitStatements:     nil,
+		OnConnect:          nil,
+		Credentials:        nil,
+		DriverName:         ClientDriver,
+		Connector:          nil,
+		Dialect:            nil,
+		QueryTimeout:       0,
+		TransactionTimeout: 0,
+		RetryPolicy:        RetryPolicy{},
+		PreloadBatchSize:   DefaultPreloadBatchSize,
 	}
 }
 
@@ -107,6 +185,20 @@ func Password(password string) Option {
 	}
 }
 
+// WithCredentialsProvider will configure the Client to retrieve its user and password from given provider
+// every time a new physical connection is opened.
+// If the provider returns an empty user, the configured one is used instead.
+// If you use WithNode, this option will be ignored.
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(options *ClientOptions) error {
+		if provider == nil {
+			return errors.New("makroud: a credentials provider is required")
+		}
+		options.Credentials = provider
+		return nil
+	}
+}
+
 // Database will configure the Client to use the given database name.
 func Database(dbname string) Option {
 	return func(options *ClientOptions) error {
@@ -132,13 +224,55 @@ func DisableSSL() Option {
 }
 
 // SSLMode will configure the Client with given SSL mode.
+// Supported modes are disable, allow, prefer, require, verify-ca and verify-full.
+// An empty mode configures the default one, which is disable.
 func SSLMode(mode string) Option {
 	return func(options *ClientOptions) error {
+		switch mode {
+		case "":
+			mode = NewClientOptions().SSLMode
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			return errors.Errorf("makroud: unsupported ssl mode: %s", mode)
+		}
 		options.SSLMode = mode
 		return nil
 	}
 }
 
+// SSLRootCert will configure the Client to verify the server certificate using given root certificate file.
+func SSLRootCert(path string) Option {
+	return func(options *ClientOptions) error {
+		if path == "" {
+			return errors.New("makroud: a root certificate path is required")
+		}
+		options.SSLRootCert = path
+		return nil
+	}
+}
+
+// SSLCert will configure the Client to authenticate using given client certificate file.
+func SSLCert(path string) Option {
+	return func(options *ClientOptions) error {
+		if path == "" {
+			return errors.New("makroud: a client certificate path is required")
+		}
+		options.SSLCert = path
+		return nil
+	}
+}
+
+// SSLKey will configure the Client to authenticate using given client private key file.
+func SSLKey(path string) Option {
+	return func(options *ClientOptions) error {
+		if path == "" {
+			return errors.New("makroud: a client private key path is required")
+		}
+		options.SSLKey = path
+		return nil
+	}
+}
+
 // Timezone will configure the Client to use given timezone.
 func Timezone(timezone string) Option {
 	return func(options *ClientOptions) error {
@@ -147,6 +281,129 @@ func Timezone(timezone string) Option {
 	}
 }
 
+// SearchPath will configure the Client to use given schemas search path.
+func SearchPath(schemas ...string) Option {
+	return func(options *ClientOptions) error {
+		if len(schemas) == 0 {
+			return errors.New("makroud: at least one schema is required for search path")
+		}
+		for i := range schemas {
+			if strings.TrimSpace(schemas[i]) == "" {
+				return errors.New("makroud: search path cannot contain an empty schema")
+			}
+		}
+		options.SearchPath = schemas
+		return nil
+	}
+}
+
+// StatementTimeout will configure the Client to abort any statement that takes more than given duration.
+// Zero means no timeout, otherwise the duration must be at least one millisecond.
+func StatementTimeout(timeout time.Duration) Option {
+	return func(options *ClientOptions) error {
+		if timeout < 0 {
+			return errors.New("makroud: the statement timeout must be a positive duration")
+		}
+		if timeout > 0 && timeout < time.Millisecond {
+			return errors.New("makroud: the statement timeout must be at least one millisecond")
+		}
+		options.StatementTimeout = timeout
+		return nil
+	}
+}
+
+// LockTimeout will configure the Client to abort any statement that waits more than given duration to acquire
+// a lock.
+// Zero means no timeout, otherwise the duration must be at least one millisecond.
+func LockTimeout(timeout time.Duration) Option {
+	return func(options *ClientOptions) error {
+		if timeout < 0 {
+			return errors.New("makroud: the lock timeout must be a positive duration")
+		}
+		if timeout > 0 && timeout < time.Millisecond {
+			return errors.New("makroud: the lock timeout must be at least one millisecond")
+		}
+		options.LockTimeout = timeout
+		return nil
+	}
+}
+
+// IdleInTransactionSessionTimeout will configure the Client to terminate any session that has been idle
+// within an open transaction for more than given duration.
+// Zero means no timeout, otherwise the duration must be at least one millisecond.
+func IdleInTransactionSessionTimeout(timeout time.Duration) Option {
+	return func(options *ClientOptions) error {
+		if timeout < 0 {
+			return errors.New("makroud: the idle in transaction session timeout must be a positive duration")
+		}
+		if timeout > 0 && timeout < time.Millisecond {
+			return errors.New("makroud: the idle in transaction session timeout must be at least one millisecond")
+		}
+		options.IdleInTxTimeout = timeout
+		return nil
+	}
+}
+
+// DefaultQueryTimeout will configure the Client to cancel a query that takes more than given duration,
+// if its context has no deadline.
+// It also applies to the preparation of a statement, and to every execution of a prepared statement.
+// Zero means no timeout. Use WithoutTimeout on a context to ignore this timeout.
+func DefaultQueryTimeout(timeout time.Duration) Option {
+	return func(options *ClientOptions) error {
+		if timeout < 0 {
+			return errors.New("makroud: the default query timeout must be a positive duration")
+		}
+		options.QueryTimeout = timeout
+		return nil
+	}
+}
+
+// DefaultTransactionTimeout will configure the Client to rollback a transaction created with Transaction that
+// takes more than given duration, if its context has no deadline.
+// Zero means no timeout. Use WithoutTimeout on a context to ignore this timeout.
+func DefaultTransactionTimeout(timeout time.Duration) Option {
+	return func(options *ClientOptions) error {
+		if timeout < 0 {
+			return errors.New("makroud: the default transaction timeout must be a positive duration")
+		}
+		options.TransactionTimeout = timeout
+		return nil
+	}
+}
+
+// WithRetryPolicy will configure the Client to retry idempotent reads, such as Select or Count, after a
+// transient connection error, using given policy.
+// Operations within a transaction are never retried, and mutations are only retried if their context has been
+// marked with MarkRetrySafe.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(options *ClientOptions) error {
+		if policy.MaxAttempts < 1 {
+			return errors.New("makroud: the maximum number of attempts must be a positive number")
+		}
+		if policy.MinBackoff < 0 || policy.MaxBackoff < 0 {
+			return errors.New("makroud: the retry backoff must be a positive duration")
+		}
+		if policy.MaxBackoff > 0 && policy.MaxBackoff < policy.MinBackoff {
+			return errors.New("makroud: the maximum retry backoff must be greater than the minimum one")
+		}
+		options.RetryPolicy = policy
+		return nil
+	}
+}
+
+// PreloadBatchSize will configure the Client to split a preload in multiple queries, so that each one uses at
+// most given number of keys.
+// By default, DefaultPreloadBatchSize is used.
+func PreloadBatchSize(size int) Option {
+	return func(options *ClientOptions) error {
+		if size < 1 {
+			return errors.New("makroud: the preload batch size must be a positive number")
+		}
+		options.PreloadBatchSize = size
+		return nil
+	}
+}
+
 // MaxOpenConnections will configure the Client to use this maximum number of open connections to the database.
 func MaxOpenConnections(maximum int) Option {
 	return func(options *ClientOptions) error {
@@ -170,6 +427,32 @@ func MaxIdleConnections(maximum int) Option {
 	}
 }
 
+// ConnMaxLifetime will configure the Client to close connections that have been opened for more than given
+// duration.
+// Zero means connections are reused forever.
+func ConnMaxLifetime(duration time.Duration) Option {
+	return func(options *ClientOptions) error {
+		if duration < 0 {
+			return errors.New("makroud: the maximum lifetime of a connection must be a positive duration")
+		}
+		options.ConnMaxLifetime = duration
+		return nil
+	}
+}
+
+// ConnMaxIdleTime will configure the Client to close connections that have been idle for more than given
+// duration.
+// Zero means connections are not closed due to idle time.
+func ConnMaxIdleTime(duration time.Duration) Option {
+	return func(options *ClientOptions) error {
+		if duration < 0 {
+			return errors.New("makroud: the maximum idle time of a connection must be a positive duration")
+		}
+		options.ConnMaxIdleTime = duration
+		return nil
+	}
+}
+
 // Cache will configure if the Client should use a cache.
 func Cache(enabled bool) Option {
 	return func(options *ClientOptions) error {
@@ -211,8 +494,47 @@ func WithEntropy(entropy io.Reader) Option {
 	}
 }
 
+// WithDriver will configure the Client to use the SQL driver registered in database/sql with given name,
+// such as "pgx" for github.com/jackc/pgx/v4/stdlib.
+// The driver must accept a PostgreSQL connection URL.
+func WithDriver(name string) Option {
+	return func(options *ClientOptions) error {
+		if name == "" {
+			return errors.New("makroud: a driver name is required")
+		}
+		options.DriverName = name
+		return nil
+	}
+}
+
+// WithConnector will configure the Client to create its driver connector using given factory,
+// instead of the one provided by the SQL driver.
+// The factory receives the connection URL generated from the Client configuration.
+func WithConnector(factory ConnectorFactory) Option {
+	return func(options *ClientOptions) error {
+		if factory == nil {
+			return errors.New("makroud: a connector factory is required")
+		}
+		options.Connector = factory
+		return nil
+	}
+}
+
+// WithDialect will configure the Client to use given SQL dialect.
+// By default, PostgreSQL dialect is used.
+func WithDialect(dialect Dialect) Option {
+	return func(options *ClientOptions) error {
+		if dialect == nil {
+			return errors.New("makroud: a dialect is required")
+		}
+		options.Dialect = dialect
+		return nil
+	}
+}
+
 // WithNode will attach a custom node connector on Client.
-// If you use this option, EnableSavepoint, MaxOpenConnections and MaxIdleConnections will be ignored.
+// If you use this option, EnableSavepoint, MaxOpenConnections, MaxIdleConnections, ConnMaxLifetime and
+// ConnMaxIdleTime will be ignored.
 func WithNode(node Node) Option {
 	return func(options *ClientOptions) error {
 		if node == nil {
@@ -223,6 +545,45 @@ func WithNode(node Node) Option {
 	}
 }
 
+// WithHooks will register given subscribers on the driver connector used by Client.
+// Subscribers are executed in registration order, see hooks.Connector for more information.
+// If you use WithNode, this option will be ignored.
+func WithHooks(subscribers ...hooks.Hooks) Option {
+	return func(options *ClientOptions) error {
+		if len(subscribers) == 0 {
+			return errors.New("makroud: at least one hooks subscriber is required")
+		}
+		options.Hooks = append(options.Hooks, subscribers...)
+		return nil
+	}
+}
+
+// InitStatements will configure the Client to execute given statements, in order, on every new physical
+// connection before its first use, including connections re-established after a failure.
+// If you use WithNode, this option will be ignored.
+func InitStatements(statements ...string) Option {
+	return func(options *ClientOptions) error {
+		if len(statements) == 0 {
+			return errors.New("makroud: at least one init statement is required")
+		}
+		options.InitStatements = append(options.InitStatements, statements...)
+		return nil
+	}
+}
+
+// OnConnect will configure the Client to execute given callback on every new physical connection before its
+// first use, after init statements.
+// If you use WithNode, this option will be ignored.
+func OnConnect(callback ConnectCallback) Option {
+	return func(options *ClientOptions) error {
+		if callback == nil {
+			return errors.New("makroud: a connect callback is required")
+		}
+		options.OnConnect = callback
+		return nil
+	}
+}
+
 // EnableSavepoint will enable the SAVEPOINT postgresql feature.
 func EnableSavepoint() Option {
 	return func(options *ClientOptions) error {
@@ -253,3 +614,39 @@ func ConnectTimeout(timeout int) Option {
 		return nil
 	}
 }
+
+// SlowQueryThreshold will configure the Client to report queries exceeding given duration to its observer.
+// The observer must implement SlowQueryObserver to receive these reports.
+// Zero or not specified means that slow query detection is disabled.
+func SlowQueryThreshold(threshold time.Duration) Option {
+	return func(options *ClientOptions) error {
+		if threshold < 0 {
+			return errors.New("makroud: the slow query threshold must be a positive duration")
+		}
+		options.SlowQueryThreshold = threshold
+		return nil
+	}
+}
+
+// EnableSlowQueryExplain will configure the Client to attach the execution plan, obtained with
+// EXPLAIN (FORMAT JSON) on a separate connection, to every slow query report.
+func EnableSlowQueryExplain() Option {
+	return func(options *ClientOptions) error {
+		options.SlowQueryExplain = true
+		return nil
+	}
+}
+
+// DetectNPlusOne will configure the Client to detect N+1 queries: when the same query shape, differing only by
+// its arguments, is executed this number of times within a query scope (see NewQueryScope), it's reported to the
+// observer. The observer must implement NPlusOneObserver to receive these reports.
+// This is a debug mode, it should not be enabled in production.
+func DetectNPlusOne(threshold int) Option {
+	return func(options *ClientOptions) error {
+		if threshold < 2 {
+			return errors.New("makroud: the N+1 detection threshold must be greater than one")
+		}
+		options.NPlusOneThreshold = threshold
+		return nil
+	}
+}
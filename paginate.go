@@ -0,0 +1,193 @@
+package makroud
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/builder"
+	"github.com/ulule/loukoum/v3/stmt"
+
+	"github.com/ulule/makroud/reflectx"
+)
+
+// Page contains metadata of a page returned by Paginate.
+type Page struct {
+	// Number is the page number, starting at 1.
+	Number int64
+	// PerPage is the maximum number of instances on a page.
+	PerPage int64
+	// Total is the number of instances matching given criteria, on every page.
+	Total int64
+	// Pages is the number of pages.
+	Pages int64
+}
+
+// HasNext returns if there is a next page.
+func (page Page) HasNext() bool {
+	return page.Number < page.Pages
+}
+
+// HasPrevious returns if there is a previous page.
+func (page Page) HasPrevious() bool {
+	return page.Number > 1
+}
+
+// Offset returns the number of instances skipped before this page.
+func (page Page) Offset() int64 {
+	return (page.Number - 1) * page.PerPage
+}
+
+// Paginate retrieves the instances of given page into given slice, and returns the page metadata.
+// It executes a COUNT(*) query, with the same criteria, to compute the total number of instances.
+// This function accepts loukoum's stmt.Order and stmt.Expression as arguments.
+func Paginate(ctx context.Context, driver Driver, dest interface{},
+	page int64, perPage int64, args ...interface{}) (*Page, error) {
+
+	return paginate(ctx, driver, dest, page, perPage, false, args)
+}
+
+// PaginateWithWindow is like Paginate, but it uses a COUNT(*) OVER() window function to retrieve the
+// instances and their total count in a single round trip.
+// If the page is beyond the last one, a COUNT(*) query is executed to compute the total count.
+func PaginateWithWindow(ctx context.Context, driver Driver, dest interface{},
+	page int64, perPage int64, args ...interface{}) (*Page, error) {
+
+	return paginate(ctx, driver, dest, page, perPage, true, args)
+}
+
+// paginateTotalColumn is the column that contains the total count when a window function is used.
+const paginateTotalColumn = "makroud_pagination_total"
+
+func paginate(ctx context.Context, driver Driver, dest interface{},
+	number int64, perPage int64, window bool, args []interface{}) (*Page, error) {
+
+	if !reflectx.IsPointer(dest) || !reflectx.IsSlice(dest) {
+		return nil, errors.Wrapf(ErrPointerRequired, "makroud: cannot execute query on %T", dest)
+	}
+
+	model, ok := reflectx.NewSliceValue(dest).(Model)
+	if !ok {
+		return nil, errors.Wrapf(ErrModelRequired, "makroud: cannot execute query on %T", dest)
+	}
+
+	schema, err := GetSchema(driver, model)
+	if err != nil {
+		return nil, errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", dest)
+	}
+
+	if number < 1 {
+		return nil, errors.Errorf("makroud: invalid page number: %d", number)
+	}
+	if perPage < 1 {
+		return nil, errors.Errorf("makroud: invalid number of instances per page: %d", perPage)
+	}
+
+	page := &Page{
+		Number:  number,
+		PerPage: perPage,
+	}
+
+	conditions := []stmt.Expression{}
+	orders := []stmt.Order{}
+	for i := range args {
+		switch value := args[i].(type) {
+		case stmt.Order:
+			orders = append(orders, value)
+		case stmt.Expression:
+			conditions = append(conditions, value)
+		}
+	}
+	if len(orders) == 0 {
+		orders = append(orders, loukoum.Order(schema.PrimaryKeyPath()))
+	}
+
+	scope := func(query builder.Select) builder.Select {
+		query = query.From(model.TableName())
+		for i := range conditions {
+			query = query.Where(conditions[i])
+		}
+		if schema.HasDeletedKey() {
+			query = query.Where(loukoum.Condition(schema.DeletedKeyPath()).IsNull(true))
+		}
+		return query
+	}
+
+	columns := schema.ColumnPaths().List()
+
+	// Instances are appended to given slice: only the ones returned by this query belong to the page.
+	offset := reflectx.GetIndirectValue(dest).Len()
+
+	if window {
+		projection := make([]interface{}, 0, len(columns)+1)
+		for i := range columns {
+			projection = append(projection, columns[i])
+		}
+		projection = append(projection, loukoum.Column("COUNT(*) OVER()").As(paginateTotalColumn))
+
+		query := scope(loukoum.Select(projection...)).OrderBy(orders...).Limit(perPage).Offset(page.Offset())
+
+		page.Total, err = execPaginateWindow(ctx, driver, query, dest, schema, columns)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		query := scope(loukoum.Select(columns)).OrderBy(orders...).Limit(perPage).Offset(page.Offset())
+
+		err = Exec(ctx, driver, query, dest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !window || reflectx.GetIndirectValue(dest).Len() == offset {
+		page.Total, err = Count(ctx, driver, scope(loukoum.Select(loukoum.Count("*"))))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	page.Pages = (page.Total + perPage - 1) / perPage
+
+	return page, nil
+}
+
+// execPaginateWindow executes given query, which returns model columns and a total count, and returns the
+// total count.
+func execPaginateWindow(ctx context.Context, driver Driver, query builder.Select, dest interface{},
+	schema *Schema, columns []string) (int64, error) {
+
+	flags := map[string]string{
+		"name":   schema.ModelName(),
+		"action": "exec-paginate-window",
+	}
+
+	total := int64(0)
+	err := queryRows(ctx, driver, query, dest, flags, func(rows Rows) error {
+		base := reflectx.GetIndirectSliceType(dest)
+		list := reflectx.GetIndirectValue(dest)
+
+		for rows.Next() {
+			model := reflectx.NewValue(base).(Model)
+
+			values, err := schema.getValues(reflectx.GetIndirectValue(model), columns, model)
+			if err != nil {
+				return err
+			}
+
+			err = rows.Scan(append(values, &total)...)
+			if err != nil {
+				return err
+			}
+
+			reflectx.AppendReflectSlice(list, model)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
@@ -0,0 +1,85 @@
+package makroud_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+func TestPaginate(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+		fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+		for _, paginate := range []func(ctx context.Context, driver makroud.Driver, dest interface{},
+			page int64, perPage int64, args ...interface{}) (*makroud.Page, error){
+			makroud.Paginate,
+			makroud.PaginateWithWindow,
+		} {
+			owls := []Owl{}
+			page, err := paginate(ctx, driver, &owls, 1, 4)
+			is.NoError(err)
+			is.NotNil(page)
+			is.Len(owls, 4)
+			is.Equal(fixtures.Owls[0].ID, owls[0].ID)
+			is.Equal(int64(1), page.Number)
+			is.Equal(int64(4), page.PerPage)
+			is.Equal(int64(6), page.Total)
+			is.Equal(int64(2), page.Pages)
+			is.True(page.HasNext())
+			is.False(page.HasPrevious())
+
+			owls = []Owl{}
+			page, err = paginate(ctx, driver, &owls, 2, 4)
+			is.NoError(err)
+			is.Len(owls, 2)
+			is.Equal(fixtures.Owls[4].ID, owls[0].ID)
+			is.Equal(int64(6), page.Total)
+			is.False(page.HasNext())
+			is.True(page.HasPrevious())
+
+			owls = []Owl{}
+			page, err = paginate(ctx, driver, &owls, 3, 4)
+			is.NoError(err)
+			is.Len(owls, 0)
+			is.Equal(int64(6), page.Total)
+			is.Equal(int64(2), page.Pages)
+
+			// Instances are appended to a non-empty slice, without changing the page.
+			owls = []Owl{{Name: "Hedwig"}}
+			page, err = paginate(ctx, driver, &owls, 2, 4)
+			is.NoError(err)
+			is.Len(owls, 3)
+			is.Equal("Hedwig", owls[0].Name)
+			is.Equal(fixtures.Owls[4].ID, owls[1].ID)
+			is.Equal(int64(6), page.Total)
+			is.Equal(int64(2), page.Pages)
+
+			owls = []Owl{{Name: "Hedwig"}}
+			page, err = paginate(ctx, driver, &owls, 3, 4)
+			is.NoError(err)
+			is.Len(owls, 1)
+			is.Equal(int64(6), page.Total)
+			is.Equal(int64(2), page.Pages)
+
+			owls = []Owl{}
+			page, err = paginate(ctx, driver, &owls, 1, 1,
+				loukoum.Condition("group_id").Equal(fixtures.Groups[0].ID),
+				loukoum.Order("name", loukoum.Desc))
+			is.NoError(err)
+			is.Len(owls, 1)
+			is.Equal("Wacky", owls[0].Name)
+			is.Equal(int64(2), page.Total)
+			is.Equal(int64(2), page.Pages)
+
+			owls = []Owl{}
+			_, err = paginate(ctx, driver, &owls, 0, 10)
+			is.Error(err)
+		}
+	})
+}
@@ -2,11 +2,13 @@ package makroud
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/ulule/loukoum/v3"
 	"github.com/ulule/loukoum/v3/builder"
+	"github.com/ulule/loukoum/v3/stmt"
 
 	"github.com/ulule/makroud/reflectx"
 )
@@ -80,9 +82,12 @@ func preload(ctx context.Context, driver Driver, rule preloadRule, dest interfac
 
 // PreloadHandler defines what resources should be preloaded.
 type PreloadHandler struct {
-	field    string
-	unscoped bool
-	callback func(query builder.Select) builder.Select
+	field     string
+	unscoped  bool
+	callback  func(query builder.Select) builder.Select
+	limit     int64
+	orders    []stmt.Order
+	aggregate *preloadAggregate
 }
 
 // WithPreloadField returns a handler that preload a field.
@@ -111,6 +116,15 @@ func WithUnscopedPreload(handler PreloadHandler) PreloadHandler {
 	return handler
 }
 
+// WithPreloadLimit limits the number of instances preloaded, for each parent, by given handler.
+// Instances are ordered using given orders, and then by their primary key.
+// It's only supported on a many association.
+func WithPreloadLimit(handler PreloadHandler, limit int64, orders ...stmt.Order) PreloadHandler {
+	handler.limit = limit
+	handler.orders = orders
+	return handler
+}
+
 // preloadGroupOperation defines, for a preload level, what resources should be preloaded.
 type preloadGroupOperation map[string]preloadOperation
 
@@ -130,11 +144,35 @@ func (o preloadOperation) Unscoped() bool {
 	return o.handler.unscoped
 }
 
+// Limit returns the maximum number of instances to preload for each parent, if any.
+func (o preloadOperation) Limit() int64 {
+	return o.handler.limit
+}
+
+// Orders returns the orders used to limit the instances to preload for each parent.
+func (o preloadOperation) Orders() []stmt.Order {
+	return o.handler.orders
+}
+
 // Path returns the preload full path.
 func (o preloadOperation) Path() string {
 	return o.handler.field
 }
 
+// Key returns the preload identifier in its group.
+// An aggregate is identified by its target field, since it can be used along a preload of the same path.
+func (o preloadOperation) Key() string {
+	if o.handler.aggregate != nil {
+		return fmt.Sprint(o.Path(), "->", o.handler.aggregate.target)
+	}
+	return o.Path()
+}
+
+// Aggregate returns the aggregate to preload for this operation, if any.
+func (o preloadOperation) Aggregate() *preloadAggregate {
+	return o.handler.aggregate
+}
+
 // Callback returns the preload conditions to execute for this operation.
 func (o preloadOperation) Callback() func(query builder.Select) builder.Select {
 	return o.handler.callback
@@ -177,7 +215,7 @@ func getPreloadGroupOperations(handlers []PreloadHandler) []preloadGroupOperatio
 		}
 
 		idx := op.Level() - 1
-		groups[idx][op.Path()] = op
+		groups[idx][op.Key()] = op
 
 	}
 
@@ -187,9 +225,11 @@ func getPreloadGroupOperations(handlers []PreloadHandler) []preloadGroupOperatio
 		// [0] -> "User"
 		// [1] -> "User.Profile"
 		// [2] -> "User.Profile.Avatar"
+		//
+		// The last level is already attached by the handler itself.
 
 		list := strings.Split(handlers[i].field, ".")
-		for i := 0; i < len(list); i++ {
+		for i := 0; i < len(list)-1; i++ {
 			n := i + 1
 			levels := list[0:n]
 			path := strings.Join(levels, ".")
@@ -271,7 +311,7 @@ func executePreloadHandler(ctx context.Context, driver Driver,
 			return errors.Wrapf(ErrPreloadInvalidPath, "'%s' is not a valid association", operation.Path())
 		}
 
-		err := handler.preload(reference, operation.Unscoped(), operation.Callback())
+		err := handler.preload(reference, operation)
 		if err != nil {
 			return err
 		}
@@ -289,8 +329,8 @@ func executePreloadWalker(ctx context.Context, driver Driver,
 		defer walker.Close()
 
 		err := walker.Find(operation.Parent(), func(values interface{}) error {
-			op := WithPreloadCallback(operation.Name(), operation.Callback())
-			op.unscoped = operation.Unscoped()
+			op := operation.handler
+			op.field = operation.Name()
 			return preload(ctx, driver, preloadRulePointerOnly, values, op)
 		})
 		if err != nil {
@@ -309,13 +349,18 @@ type preloadHandler struct {
 	dest   interface{}
 }
 
-func (handler *preloadHandler) preload(reference Reference, unscoped bool,
-	callback func(query builder.Select) builder.Select) error {
-
+func (handler *preloadHandler) preload(reference Reference, operation preloadOperation) error {
+	if operation.Aggregate() != nil {
+		return handler.preloadAggregate(reference, operation)
+	}
 	if reference.IsAssociationType(AssociationTypeOne) {
-		return handler.preloadOne(reference, unscoped, callback)
+		if operation.Limit() > 0 {
+			return errors.Wrapf(ErrPreloadInvalidPath,
+				"cannot limit preload of '%s', a many association is required", operation.Path())
+		}
+		return handler.preloadOne(reference, operation.Unscoped(), operation.Callback())
 	}
-	return handler.preloadMany(reference, unscoped, callback)
+	return handler.preloadMany(reference, operation)
 }
 
 func (handler *preloadHandler) preloadOne(reference Reference, unscoped bool,
@@ -349,7 +394,7 @@ func (handler *preloadHandler) preloadOneLocal(reference Reference, unscoped boo
 		preloader := reflectx.NewStringPreloader(reference.FieldName(), reference.Type(), handler.dest)
 		defer preloader.Close()
 
-		return handler.preloadString(preloader, reference, builder,
+		return handler.preloadString(preloader, reference, builder, preloadWindow{},
 			getPreloadForEachCallbackLocalString(preloader, reference))
 
 	case FKIntegerType:
@@ -357,7 +402,7 @@ func (handler *preloadHandler) preloadOneLocal(reference Reference, unscoped boo
 		preloader := reflectx.NewIntegerPreloader(reference.FieldName(), reference.Type(), handler.dest)
 		defer preloader.Close()
 
-		return handler.preloadInteger(preloader, reference, builder,
+		return handler.preloadInteger(preloader, reference, builder, preloadWindow{},
 			getPreloadForEachCallbackLocalInteger(preloader, reference))
 
 	case FKOptionalStringType:
@@ -365,7 +410,7 @@ func (handler *preloadHandler) preloadOneLocal(reference Reference, unscoped boo
 		preloader := reflectx.NewStringPreloader(reference.FieldName(), reference.Type(), handler.dest)
 		defer preloader.Close()
 
-		return handler.preloadString(preloader, reference, builder,
+		return handler.preloadString(preloader, reference, builder, preloadWindow{},
 			getPreloadForEachCallbackLocalOptionalString(preloader, reference))
 
 	case FKOptionalIntegerType:
@@ -373,7 +418,7 @@ func (handler *preloadHandler) preloadOneLocal(reference Reference, unscoped boo
 		preloader := reflectx.NewIntegerPreloader(reference.FieldName(), reference.Type(), handler.dest)
 		defer preloader.Close()
 
-		return handler.preloadInteger(preloader, reference, builder,
+		return handler.preloadInteger(preloader, reference, builder, preloadWindow{},
 			getPreloadForEachCallbackLocalOptionalInteger(preloader, reference))
 
 	default:
@@ -403,7 +448,7 @@ func (handler *preloadHandler) preloadOneRemote(reference Reference, unscoped bo
 		preloader := reflectx.NewStringPreloader(reference.FieldName(), reference.Type(), handler.dest)
 		defer preloader.Close()
 
-		return handler.preloadString(preloader, reference, builder,
+		return handler.preloadString(preloader, reference, builder, preloadWindow{},
 			getPreloadForEachCallbackRemoteString(preloader, reference))
 
 	case PKIntegerType:
@@ -411,7 +456,7 @@ func (handler *preloadHandler) preloadOneRemote(reference Reference, unscoped bo
 		preloader := reflectx.NewIntegerPreloader(reference.FieldName(), reference.Type(), handler.dest)
 		defer preloader.Close()
 
-		return handler.preloadInteger(preloader, reference, builder,
+		return handler.preloadInteger(preloader, reference, builder, preloadWindow{},
 			getPreloadForEachCallbackRemoteInteger(preloader, reference))
 
 	default:
@@ -419,9 +464,7 @@ func (handler *preloadHandler) preloadOneRemote(reference Reference, unscoped bo
 	}
 }
 
-func (handler *preloadHandler) preloadMany(reference Reference, unscoped bool,
-	callback func(query builder.Select) builder.Select) error {
-
+func (handler *preloadHandler) preloadMany(reference Reference, operation preloadOperation) error {
 	remote := reference.Remote()
 	local := reference.Local()
 
@@ -430,8 +473,14 @@ func (handler *preloadHandler) preloadMany(reference Reference, unscoped bool,
 		return err
 	}
 
-	builder := callback(loukoum.Select(remote.Columns()).From(remote.TableName()))
-	if remote.HasDeletedKey() && !unscoped {
+	window, err := getPreloadWindow(reference, operation)
+	if err != nil {
+		return err
+	}
+
+	callback := operation.Callback()
+	builder := callback(loukoum.Select(window.projection()...).From(remote.TableName()))
+	if remote.HasDeletedKey() && !operation.Unscoped() {
 		builder = builder.Where(loukoum.Condition(remote.DeletedKeyPath()).IsNull(true))
 	}
 
@@ -441,7 +490,7 @@ func (handler *preloadHandler) preloadMany(reference Reference, unscoped bool,
 		preloader := reflectx.NewStringPreloader(reference.FieldName(), reference.Type(), handler.dest)
 		defer preloader.Close()
 
-		return handler.preloadString(preloader, reference, builder,
+		return handler.preloadString(preloader, reference, builder, window,
 			getPreloadForEachCallbackRemoteString(preloader, reference))
 
 	case PKIntegerType:
@@ -449,7 +498,7 @@ func (handler *preloadHandler) preloadMany(reference Reference, unscoped bool,
 		preloader := reflectx.NewIntegerPreloader(reference.FieldName(), reference.Type(), handler.dest)
 		defer preloader.Close()
 
-		return handler.preloadInteger(preloader, reference, builder,
+		return handler.preloadInteger(preloader, reference, builder, window,
 			getPreloadForEachCallbackRemoteInteger(preloader, reference))
 
 	default:
@@ -536,7 +585,7 @@ func getPreloadForEachCallbackLocalOptionalInteger(preloader *reflectx.IntegerPr
 }
 
 func (handler *preloadHandler) preloadString(preloader *reflectx.StringPreloader, reference Reference,
-	builder builder.Select, preloadCallback func(element reflectx.PreloadValue) error) error {
+	builder builder.Select, window preloadWindow, preloadCallback func(element reflectx.PreloadValue) error) error {
 
 	remote := reference.Remote()
 
@@ -550,12 +599,13 @@ func (handler *preloadHandler) preloadString(preloader *reflectx.StringPreloader
 		return nil
 	}
 
-	builder = builder.Where(loukoum.Condition(remote.ColumnPath()).In(list))
-
 	err = preloader.OnExecute(func(relation interface{}) error {
-		err := Exec(handler.ctx, handler.driver, builder, relation)
-		if err != nil && !IsErrNoRows(err) {
-			return err
+		for _, chunk := range chunkPreloadIndexes(list, handler.driver.PreloadBatchSize()) {
+			query := builder.Where(loukoum.Condition(remote.ColumnPath()).In(chunk))
+			err := Exec(handler.ctx, handler.driver, window.wrap(query), relation)
+			if err != nil && !IsErrNoRows(err) {
+				return err
+			}
 		}
 		return nil
 	})
@@ -581,8 +631,8 @@ func (handler *preloadHandler) preloadString(preloader *reflectx.StringPreloader
 	return nil
 }
 
-func (handler *preloadHandler) preloadInteger(preloader *reflectx.IntegerPreloader,
-	reference Reference, builder builder.Select, preloadCallback func(element reflectx.PreloadValue) error) error {
+func (handler *preloadHandler) preloadInteger(preloader *reflectx.IntegerPreloader, reference Reference,
+	builder builder.Select, window preloadWindow, preloadCallback func(element reflectx.PreloadValue) error) error {
 
 	remote := reference.Remote()
 
@@ -596,12 +646,13 @@ func (handler *preloadHandler) preloadInteger(preloader *reflectx.IntegerPreload
 		return nil
 	}
 
-	builder = builder.Where(loukoum.Condition(remote.ColumnPath()).In(list))
-
 	err = preloader.OnExecute(func(relation interface{}) error {
-		err := Exec(handler.ctx, handler.driver, builder, relation)
-		if err != nil && !IsErrNoRows(err) {
-			return err
+		for _, chunk := range chunkPreloadIndexes(list, handler.driver.PreloadBatchSize()) {
+			query := builder.Where(loukoum.Condition(remote.ColumnPath()).In(chunk))
+			err := Exec(handler.ctx, handler.driver, window.wrap(query), relation)
+			if err != nil && !IsErrNoRows(err) {
+				return err
+			}
 		}
 		return nil
 	})
@@ -782,3 +833,19 @@ func preloadCheckRemoteForeignKey(reference Reference, local ReferenceObject, re
 	}
 	return nil
 }
+
+// chunkPreloadIndexes splits given keys in chunks of given size, so that a preload query doesn't exceed the
+// bind parameters limit of the database.
+func chunkPreloadIndexes[T int64 | string](list []T, size int) [][]T {
+	if size <= 0 || len(list) <= size {
+		return [][]T{list}
+	}
+
+	chunks := make([][]T, 0, (len(list)+size-1)/size)
+	for len(list) > size {
+		chunks = append(chunks, list[:size:size])
+		list = list[size:]
+	}
+
+	return append(chunks, list)
+}
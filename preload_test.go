@@ -5756,3 +5756,284 @@ func TestPreload_Zootopia_MultiLevel(t *testing.T) {
 		}
 	})
 }
+
+func TestPreload_Cat_Limit(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		{
+
+			fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+			cats := []*Cat{
+				fixtures.Cats[0],
+				fixtures.Cats[1],
+				fixtures.Cats[2],
+				fixtures.Cats[3],
+				fixtures.Cats[7],
+			}
+
+			err := makroud.Preload(ctx, driver, &cats,
+				makroud.WithPreloadLimit(makroud.WithPreloadField("Meows"), 2, loukoum.Order("body", loukoum.Desc)),
+			)
+			is.NoError(err)
+			is.Len(cats, 5)
+
+			is.Len(cats[0].Meows, 2)
+			is.Equal(fixtures.Meows[2].Hash, cats[0].Meows[0].Hash)
+			is.Equal(fixtures.Meows[1].Hash, cats[0].Meows[1].Hash)
+			is.Empty(cats[1].Meows)
+			is.Len(cats[2].Meows, 1)
+			is.Equal(fixtures.Meows[3].Hash, cats[2].Meows[0].Hash)
+			is.Len(cats[3].Meows, 2)
+			is.Equal(fixtures.Meows[6].Hash, cats[3].Meows[0].Hash)
+			is.Equal(fixtures.Meows[5].Hash, cats[3].Meows[1].Hash)
+			is.Len(cats[4].Meows, 2)
+			is.Equal(fixtures.Meows[14].Hash, cats[4].Meows[0].Hash)
+			is.Equal(fixtures.Meows[13].Hash, cats[4].Meows[1].Hash)
+
+		}
+		{
+
+			fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+			cat := fixtures.Cats[7]
+
+			err := makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadLimit(makroud.WithPreloadCallback("Meows", func(query builder.Select) builder.Select {
+					return query.Where(loukoum.Condition("body").NotEqual(fixtures.Meows[10].Body))
+				}), 3, loukoum.Order("body", loukoum.Asc)),
+			)
+			is.NoError(err)
+			is.Len(cat.Meows, 3)
+			is.Equal(fixtures.Meows[11].Hash, cat.Meows[0].Hash)
+			is.Equal(fixtures.Meows[12].Hash, cat.Meows[1].Hash)
+			is.Equal(fixtures.Meows[13].Hash, cat.Meows[2].Hash)
+
+		}
+		{
+
+			fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+			humans := []*Human{
+				fixtures.Humans[0],
+				fixtures.Humans[3],
+			}
+
+			err := makroud.Preload(ctx, driver, &humans,
+				makroud.WithPreloadField("Cat"),
+				makroud.WithPreloadLimit(makroud.WithPreloadField("Cat.Meows"), 1, loukoum.Order("body", loukoum.Desc)),
+			)
+			is.NoError(err)
+			is.NotNil(humans[0].Cat)
+			is.Len(humans[0].Cat.Meows, 1)
+			is.Equal(fixtures.Meows[2].Hash, humans[0].Cat.Meows[0].Hash)
+			is.NotNil(humans[1].Cat)
+			is.Len(humans[1].Cat.Meows, 1)
+			is.Equal(fixtures.Meows[6].Hash, humans[1].Cat.Meows[0].Hash)
+
+		}
+		{
+
+			fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+			cat := fixtures.Cats[0]
+
+			err := makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadLimit(makroud.WithPreloadField("Feeder"), 1),
+			)
+			is.Error(err)
+			is.Equal(makroud.ErrPreloadInvalidPath, errors.Cause(err))
+
+			err = makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadLimit(makroud.WithPreloadField("Meows"), 1, loukoum.Order("unknown")),
+			)
+			is.Error(err)
+			is.Equal(makroud.ErrSchemaColumnRequired, errors.Cause(err))
+
+		}
+	})
+}
+
+func TestPreload_Cat_Aggregate(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		{
+
+			fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+			cats := []Cat{
+				*fixtures.Cats[0],
+				*fixtures.Cats[1],
+				*fixtures.Cats[2],
+				*fixtures.Cats[3],
+				*fixtures.Cats[7],
+			}
+			cats[1].MeowsCount = 42
+
+			err := makroud.Preload(ctx, driver, &cats,
+				makroud.WithPreloadCount("Meows", "MeowsCount"),
+			)
+			is.NoError(err)
+			is.Len(cats, 5)
+
+			is.Equal(int64(3), cats[0].MeowsCount)
+			is.Equal(int64(0), cats[1].MeowsCount)
+			is.Equal(int64(1), cats[2].MeowsCount)
+			is.Equal(int64(3), cats[3].MeowsCount)
+			is.Equal(int64(5), cats[4].MeowsCount)
+			is.Empty(cats[0].Meows)
+			is.Empty(cats[4].Meows)
+
+		}
+		{
+
+			fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+			cat := fixtures.Cats[7]
+
+			err := makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadField("Meows"),
+				makroud.WithPreloadAggregate("Meows", "MeowsCount", makroud.AggregateCount, "body"),
+			)
+			is.NoError(err)
+			is.Equal(int64(5), cat.MeowsCount)
+			is.Len(cat.Meows, 5)
+
+			err = makroud.Archive(ctx, driver, cat.Meows[0])
+			is.NoError(err)
+
+			err = makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadCount("Meows", "MeowsCount"),
+			)
+			is.NoError(err)
+			is.Equal(int64(4), cat.MeowsCount)
+
+			err = makroud.Preload(ctx, driver, cat,
+				makroud.WithUnscopedPreload(makroud.WithPreloadCount("Meows", "MeowsCount")),
+			)
+			is.NoError(err)
+			is.Equal(int64(5), cat.MeowsCount)
+
+		}
+		{
+
+			fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+			humans := []*Human{
+				fixtures.Humans[0],
+				fixtures.Humans[6],
+			}
+
+			err := makroud.Preload(ctx, driver, &humans,
+				makroud.WithPreloadCount("Cat.Meows", "MeowsCount"),
+			)
+			is.NoError(err)
+			is.NotNil(humans[0].Cat)
+			is.Equal(int64(3), humans[0].Cat.MeowsCount)
+			is.Empty(humans[0].Cat.Meows)
+			is.NotNil(humans[1].Cat)
+			is.Equal(int64(2), humans[1].Cat.MeowsCount)
+			is.Empty(humans[1].Cat.Meows)
+
+		}
+		{
+
+			fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+			cat := fixtures.Cats[0]
+
+			err := makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadCount("Feeder", "MeowsCount"),
+			)
+			is.Error(err)
+			is.Equal(makroud.ErrPreloadInvalidPath, errors.Cause(err))
+
+			err = makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadCount("Meows", "Name"),
+			)
+			is.Error(err)
+			is.Equal(makroud.ErrPreloadInvalidModel, errors.Cause(err))
+
+			err = makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadCount("Meows", "Unknown"),
+			)
+			is.Error(err)
+			is.Equal(makroud.ErrPreloadInvalidModel, errors.Cause(err))
+
+			err = makroud.Preload(ctx, driver, cat,
+				makroud.WithPreloadAggregate("Meows", "MeowsCount", makroud.AggregateMax, "unknown"),
+			)
+			is.Error(err)
+			is.Equal(makroud.ErrSchemaColumnRequired, errors.Cause(err))
+
+		}
+	})
+}
+
+func TestPreload_BatchSize(t *testing.T) {
+	is := require.New(t)
+
+	_, err := makroud.New(makroud.PreloadBatchSize(0))
+	is.Error(err)
+
+	Setup(t, makroud.PreloadBatchSize(3))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		is.Equal(3, driver.PreloadBatchSize())
+
+		fixtures := GenerateZootopiaFixtures(ctx, driver, is)
+
+		cats := []Cat{
+			*fixtures.Cats[0],
+			*fixtures.Cats[1],
+			*fixtures.Cats[2],
+			*fixtures.Cats[3],
+			*fixtures.Cats[4],
+			*fixtures.Cats[5],
+			*fixtures.Cats[6],
+			*fixtures.Cats[7],
+		}
+
+		err := makroud.Preload(ctx, driver, &cats,
+			makroud.WithPreloadField("Feeder"),
+			makroud.WithPreloadField("Meows"),
+			makroud.WithPreloadCount("Meows", "MeowsCount"),
+		)
+		is.NoError(err)
+		is.Len(cats, 8)
+
+		is.NotNil(cats[0].Feeder)
+		is.Equal(fixtures.Humans[0].ID, cats[0].Feeder.ID)
+		is.NotNil(cats[3].Feeder)
+		is.Equal(fixtures.Humans[3].ID, cats[3].Feeder.ID)
+		is.NotNil(cats[6].Feeder)
+		is.Equal(fixtures.Humans[6].ID, cats[6].Feeder.ID)
+		is.Nil(cats[7].Feeder)
+
+		expected := []int{3, 0, 1, 3, 1, 0, 2, 5}
+		for i := range cats {
+			is.Len(cats[i].Meows, expected[i])
+			is.Equal(int64(expected[i]), cats[i].MeowsCount)
+		}
+
+		meows := []*Meow{}
+		for i := range fixtures.Meows {
+			meows = append(meows, &Meow{
+				Hash:  fixtures.Meows[i].Hash,
+				CatID: fixtures.Meows[i].CatID,
+			})
+		}
+
+		err = makroud.Preload(ctx, driver, &meows, makroud.WithPreloadField("Cat"))
+		is.NoError(err)
+		for i := range meows {
+			is.NotNil(meows[i].Cat)
+			is.Equal(fixtures.Meows[i].CatID, meows[i].Cat.ID)
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package makroud
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/builder"
+
+	"github.com/ulule/makroud/reflectx"
+)
+
+// AggregateFunction defines an aggregate function that can be preloaded with WithPreloadAggregate.
+type AggregateFunction string
+
+// Aggregate functions.
+const (
+	AggregateCount = AggregateFunction("COUNT")
+	AggregateSum   = AggregateFunction("SUM")
+	AggregateAvg   = AggregateFunction("AVG")
+	AggregateMin   = AggregateFunction("MIN")
+	AggregateMax   = AggregateFunction("MAX")
+)
+
+const (
+	// preloadAggregateKeyColumn is the column that contains the foreign key of an aggregate.
+	preloadAggregateKeyColumn = "makroud_aggregate_key"
+	// preloadAggregateValueColumn is the column that contains the value of an aggregate.
+	preloadAggregateValueColumn = "makroud_aggregate_value"
+)
+
+var (
+	preloadAggregateInt64Type   = reflect.TypeOf(int64(0))
+	preloadAggregateFloat64Type = reflect.TypeOf(float64(0))
+)
+
+// preloadAggregate defines an aggregate function, on a many association, to preload in a field of its parent.
+type preloadAggregate struct {
+	target   string
+	function AggregateFunction
+	column   string
+}
+
+// WithPreloadCount returns a handler that preloads, in given target field, the number of instances of given many
+// association, without retrieving them.
+// The target field must be an int64, and it should be ignored by makroud, using the "-" tag.
+func WithPreloadCount(field string, target string) PreloadHandler {
+	return WithPreloadAggregate(field, target, AggregateCount, "*")
+}
+
+// WithPreloadAggregate returns a handler that preloads, in given target field, the result of given aggregate
+// function on a column of given many association, without retrieving its instances.
+// The target field must be an int64 or a float64, and it should be ignored by makroud, using the "-" tag.
+// If the association has no instance, the target field is set to zero.
+func WithPreloadAggregate(field string, target string, function AggregateFunction, column string) PreloadHandler {
+	handler := WithPreloadField(field)
+	handler.aggregate = &preloadAggregate{
+		target:   target,
+		function: function,
+		column:   column,
+	}
+	return handler
+}
+
+// expression returns the aggregate expression on given association.
+func (aggregate preloadAggregate) expression(remote ReferenceObject) (string, error) {
+	switch aggregate.function {
+	case AggregateCount, AggregateSum, AggregateAvg, AggregateMin, AggregateMax:
+	default:
+		return "", errors.Errorf("'%s' is a unsupported aggregate function for preload", aggregate.function)
+	}
+
+	column := aggregate.column
+	if aggregate.function == AggregateCount && column == "*" {
+		return fmt.Sprint(aggregate.function, "(*)"), nil
+	}
+
+	if !remote.Schema().HasColumn(column) {
+		return "", errors.Wrapf(ErrSchemaColumnRequired,
+			"cannot use column %s in aggregate of %s", column, remote.ModelName())
+	}
+	if !strings.Contains(column, ".") {
+		column = fmt.Sprint(remote.TableName(), ".", column)
+	}
+
+	return fmt.Sprint(aggregate.function, "(", column, ")"), nil
+}
+
+// preloadAggregateValue is used to scan the value of an aggregate, according to its target type.
+type preloadAggregateValue struct {
+	kind    reflect.Type
+	integer sql.NullInt64
+	float   sql.NullFloat64
+}
+
+// Dest returns the scan destination of the value.
+func (value *preloadAggregateValue) Dest() interface{} {
+	if value.kind == preloadAggregateInt64Type {
+		return &value.integer
+	}
+	return &value.float
+}
+
+// Value returns the scanned value, or a zero value if it's null.
+func (value *preloadAggregateValue) Value() interface{} {
+	if value.kind == preloadAggregateInt64Type {
+		return value.integer.Int64
+	}
+	return value.float.Float64
+}
+
+// Zero returns the zero value of the target.
+func (value *preloadAggregateValue) Zero() interface{} {
+	if value.kind == preloadAggregateInt64Type {
+		return int64(0)
+	}
+	return float64(0)
+}
+
+func (handler *preloadHandler) preloadAggregate(reference Reference, operation preloadOperation) error {
+	if reference.IsAssociationType(AssociationTypeOne) {
+		return errors.Wrapf(ErrPreloadInvalidPath,
+			"cannot preload aggregate of '%s', a many association is required", operation.Path())
+	}
+
+	remote := reference.Remote()
+	local := reference.Local()
+	aggregate := operation.Aggregate()
+
+	err := preloadCheckRemoteForeignKey(reference, local, remote)
+	if err != nil {
+		return err
+	}
+
+	field, ok := reflectx.GetFieldByName(handler.model, aggregate.target)
+	if !ok {
+		return errors.Wrapf(ErrPreloadInvalidModel,
+			"cannot find field '%s' to preload aggregate of '%s'", aggregate.target, operation.Path())
+	}
+	if field.Type != preloadAggregateInt64Type && field.Type != preloadAggregateFloat64Type {
+		return errors.Wrapf(ErrPreloadInvalidModel,
+			"cannot preload aggregate of '%s' in field '%s', an int64 or a float64 is required",
+			operation.Path(), aggregate.target)
+	}
+
+	expression, err := aggregate.expression(remote)
+	if err != nil {
+		return err
+	}
+
+	builder := loukoum.Select(
+		loukoum.Column(remote.ColumnPath()).As(preloadAggregateKeyColumn),
+		loukoum.Column(expression).As(preloadAggregateValueColumn),
+	).From(remote.TableName()).GroupBy(remote.ColumnPath())
+	if remote.HasDeletedKey() && !operation.Unscoped() {
+		builder = builder.Where(loukoum.Condition(remote.DeletedKeyPath()).IsNull(true))
+	}
+
+	value := &preloadAggregateValue{
+		kind: field.Type,
+	}
+
+	switch local.PrimaryKeyType() {
+	case PKStringType:
+
+		preloader := reflectx.NewStringPreloader(aggregate.target, field.Type, handler.dest)
+		defer preloader.Close()
+
+		return handler.preloadAggregateString(preloader, reference, builder, value,
+			getPreloadForEachCallbackAggregate(aggregate, value,
+				getPreloadForEachCallbackRemoteString(preloader, reference)))
+
+	case PKIntegerType:
+
+		preloader := reflectx.NewIntegerPreloader(aggregate.target, field.Type, handler.dest)
+		defer preloader.Close()
+
+		return handler.preloadAggregateInteger(preloader, reference, builder, value,
+			getPreloadForEachCallbackAggregate(aggregate, value,
+				getPreloadForEachCallbackRemoteInteger(preloader, reference)))
+
+	default:
+		return errors.Errorf("'%s' is a unsupported primary key type for preload", reference.Type())
+	}
+}
+
+func (handler *preloadHandler) preloadAggregateString(preloader *reflectx.StringPreloader, reference Reference,
+	builder builder.Select, value *preloadAggregateValue,
+	preloadCallback func(element reflectx.PreloadValue) error) error {
+
+	err := preloader.ForEach(preloadCallback)
+	if err != nil {
+		return err
+	}
+
+	list := preloader.Indexes()
+	if len(list) == 0 {
+		return nil
+	}
+
+	return execPreloadAggregate(handler, reference, builder, list, func(rows Rows) error {
+		fk := ""
+		err := rows.Scan(&fk, value.Dest())
+		if err != nil {
+			return err
+		}
+
+		return preloader.UpdateValueOnIndex(fk, value.Value())
+	})
+}
+
+func (handler *preloadHandler) preloadAggregateInteger(preloader *reflectx.IntegerPreloader, reference Reference,
+	builder builder.Select, value *preloadAggregateValue,
+	preloadCallback func(element reflectx.PreloadValue) error) error {
+
+	err := preloader.ForEach(preloadCallback)
+	if err != nil {
+		return err
+	}
+
+	list := preloader.Indexes()
+	if len(list) == 0 {
+		return nil
+	}
+
+	return execPreloadAggregate(handler, reference, builder, list, func(rows Rows) error {
+		fk := int64(0)
+		err := rows.Scan(&fk, value.Dest())
+		if err != nil {
+			return err
+		}
+
+		return preloader.UpdateValueOnIndex(fk, value.Value())
+	})
+}
+
+// execPreloadAggregate executes given aggregate query on given keys, and calls given callback on each of its
+// rows.
+func execPreloadAggregate[T int64 | string](handler *preloadHandler, reference Reference, builder builder.Select,
+	list []T, callback func(rows Rows) error) error {
+
+	remote := reference.Remote()
+	flags := map[string]string{
+		"name":   remote.ModelName(),
+		"action": "preload-aggregate",
+	}
+
+	for _, chunk := range chunkPreloadIndexes(list, handler.driver.PreloadBatchSize()) {
+		query := builder.Where(loukoum.Condition(remote.ColumnPath()).In(chunk))
+		err := queryRows(handler.ctx, handler.driver, query, nil, flags, func(rows Rows) error {
+			for rows.Next() {
+				err := callback(rows)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getPreloadForEachCallbackAggregate resets the target field of every parent before executing given callback,
+// so that a parent without any instance has a zero value.
+func getPreloadForEachCallbackAggregate(aggregate *preloadAggregate, value *preloadAggregateValue,
+	callback func(element reflectx.PreloadValue) error) func(element reflectx.PreloadValue) error {
+
+	return func(element reflectx.PreloadValue) error {
+		err := reflectx.UpdateFieldValue(element.Unwrap(), aggregate.target, value.Zero())
+		if err != nil {
+			return err
+		}
+		return callback(element)
+	}
+}
@@ -0,0 +1,156 @@
+package makroud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/builder"
+	"github.com/ulule/loukoum/v3/stmt"
+	"github.com/ulule/loukoum/v3/types"
+)
+
+const (
+	// preloadWindowAlias is the alias of a ranked preload query, once wrapped in a subquery.
+	preloadWindowAlias = "makroud_preload"
+	// preloadWindowColumn is the column that contains the rank of an instance for its parent.
+	preloadWindowColumn = "makroud_row_number"
+)
+
+// preloadWindow limits the number of instances returned, for each parent, by a preload query.
+// The query is ranked with a ROW_NUMBER() window function partitioned by the foreign key, and then wrapped in
+// a subquery filtering on this rank.
+type preloadWindow struct {
+	columns []string
+	limit   int64
+	rank    string
+}
+
+// getPreloadWindow returns the preload window of given operation on a many association.
+func getPreloadWindow(reference Reference, operation preloadOperation) (preloadWindow, error) {
+	remote := reference.Remote()
+	schema := remote.Schema()
+
+	window := preloadWindow{
+		columns: remote.Columns(),
+		limit:   operation.Limit(),
+	}
+	if window.limit < 0 {
+		return window, errors.Errorf("invalid limit on preload of '%s': %d", operation.Path(), window.limit)
+	}
+	if window.limit == 0 {
+		return window, nil
+	}
+
+	orders := make([]string, 0, len(operation.Orders())+1)
+	hasPK := false
+
+	for _, order := range operation.Orders() {
+		if !schema.HasColumn(order.Expression) {
+			return window, errors.Wrapf(ErrSchemaColumnRequired,
+				"cannot use column %s to limit preload of '%s'", order.Expression, operation.Path())
+		}
+		if order.Expression == schema.PrimaryKeyName() || order.Expression == schema.PrimaryKeyPath() {
+			hasPK = true
+		}
+
+		kind := order.Type
+		if kind == "" {
+			kind = loukoum.Asc
+		}
+
+		orders = append(orders, fmt.Sprint(order.Expression, " ", kind))
+	}
+
+	// Primary key is used to handle ties, so that the same instances are always returned.
+	if !hasPK {
+		orders = append(orders, fmt.Sprint(schema.PrimaryKeyPath(), " ", loukoum.Asc))
+	}
+
+	window.rank = fmt.Sprint("ROW_NUMBER() OVER (PARTITION BY ", remote.ColumnPath(),
+		" ORDER BY ", strings.Join(orders, ", "), ")")
+
+	return window, nil
+}
+
+// projection returns the columns of the preload query, with the rank of each instance if required.
+func (window preloadWindow) projection() []interface{} {
+	columns := make([]interface{}, 0, len(window.columns)+1)
+	for i := range window.columns {
+		columns = append(columns, window.columns[i])
+	}
+	if window.limit > 0 {
+		columns = append(columns, loukoum.Column(window.rank).As(preloadWindowColumn))
+	}
+	return columns
+}
+
+// wrap returns the query to execute for given preload query.
+func (window preloadWindow) wrap(query builder.Select) builder.Builder {
+	if window.limit <= 0 {
+		return query
+	}
+	return preloadWindowQuery{
+		query:   query,
+		columns: window.columns,
+		limit:   window.limit,
+	}
+}
+
+// preloadWindowQuery is a loukoum builder that keeps, from a ranked preload query, only the instances whose
+// rank is lower or equal to given limit.
+type preloadWindowQuery struct {
+	query   builder.Select
+	columns []string
+	limit   int64
+}
+
+// String returns the underlying query as a raw statement.
+func (query preloadWindowQuery) String() string {
+	ctx := &types.RawContext{}
+	query.Write(ctx)
+	return ctx.Query()
+}
+
+// NamedQuery returns the underlying query as a named statement.
+func (query preloadWindowQuery) NamedQuery() (string, map[string]interface{}) {
+	ctx := &types.NamedContext{}
+	query.Write(ctx)
+	return ctx.Query(), ctx.Values()
+}
+
+// Query returns the underlying query as a regular statement.
+func (query preloadWindowQuery) Query() (string, []interface{}) {
+	ctx := &types.StdContext{}
+	query.Write(ctx)
+	return ctx.Query(), ctx.Values()
+}
+
+// Statement returns underlying statement.
+func (query preloadWindowQuery) Statement() stmt.Statement {
+	return query
+}
+
+// IsEmpty returns true if statement is undefined.
+func (query preloadWindowQuery) IsEmpty() bool {
+	return query.query.Statement().IsEmpty()
+}
+
+// Write exposes statement as a SQL query.
+func (query preloadWindowQuery) Write(ctx types.Context) {
+	ctx.Write("SELECT ")
+	for i, column := range query.columns {
+		if i > 0 {
+			ctx.Write(", ")
+		}
+		ctx.Write(fmt.Sprint(preloadWindowAlias, ".", column))
+	}
+
+	ctx.Write(" FROM (")
+	query.query.Statement().Write(ctx)
+	ctx.Write(fmt.Sprint(") AS ", preloadWindowAlias))
+
+	rank := fmt.Sprint(preloadWindowAlias, ".", preloadWindowColumn)
+	ctx.Write(fmt.Sprint(" WHERE ", rank, " <= ", query.limit, " ORDER BY ", rank))
+}
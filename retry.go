@@ -0,0 +1,147 @@
+package makroud
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/ulule/loukoum/v3/builder"
+)
+
+// RetryPolicy defines how idempotent reads are retried after a transient connection error, for example, when
+// a replica restarts.
+// Operations within a transaction are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value lower than two disables retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, which is doubled on every retry.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts.
+	// Zero means the delay is doubled without limit.
+	MaxBackoff time.Duration
+}
+
+// Backoff returns the delay to wait after given attempt, with a random jitter of up to half the delay.
+// The delay is doubled on every attempt, until MaxBackoff if it's defined.
+func (policy RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := policy.MinBackoff
+	for i := 1; i < attempt && delay > 0; i++ {
+		if policy.MaxBackoff > 0 && delay >= policy.MaxBackoff {
+			break
+		}
+		if delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := int64(delay / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+type retryKey struct{}
+
+// MarkRetrySafe returns a new context on which every operation executed with Exec, RawExec or RawExecArgs
+// is considered safe to retry, such as an idempotent mutation.
+// By default, only SELECT builders are retried.
+func MarkRetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// isRetrySafe returns if given statement can be retried.
+func isRetrySafe(ctx context.Context, stmt builder.Builder) bool {
+	safe, _ := ctx.Value(retryKey{}).(bool)
+	if safe {
+		return true
+	}
+
+	switch stmt.(type) {
+	case builder.Select, *builder.Select, preloadWindowQuery:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableError returns if given error is a transient error that may not happen on another attempt.
+func isRetryableError(err error) bool {
+	if isConnectionError(err) {
+		return true
+	}
+
+	driverErr, ok := AsDriverError(err)
+	if !ok {
+		return false
+	}
+
+	switch driverErr.Code {
+	case "57P01", "57P02", "57P03":
+		// admin_shutdown, crash_shutdown and cannot_connect_now.
+		return true
+	default:
+		return false
+	}
+}
+
+// retry executes given handler until it succeeds, or until the retry policy of driver is exhausted.
+// Destination slices are truncated to their initial length before every new attempt.
+func retry(ctx context.Context, driver Driver, handler func() error, dest ...interface{}) error {
+	policy := driver.RetryPolicy()
+	if policy.MaxAttempts < 2 || driver.InTransaction() {
+		return handler()
+	}
+
+	reset := truncateSlices(dest)
+
+	for attempt := 1; ; attempt++ {
+		err := handler()
+		if err == nil || attempt >= policy.MaxAttempts || !isRetryableError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		reset()
+	}
+}
+
+// truncateSlices returns a function that restores given slice pointers to their current length.
+func truncateSlices(dest []interface{}) func() {
+	type snapshot struct {
+		value  reflect.Value
+		length int
+	}
+
+	snapshots := []snapshot{}
+	for i := range dest {
+		value := reflect.ValueOf(dest[i])
+		if value.Kind() != reflect.Ptr || value.IsNil() {
+			continue
+		}
+		value = value.Elem()
+		if value.Kind() == reflect.Slice {
+			snapshots = append(snapshots, snapshot{value: value, length: value.Len()})
+		}
+	}
+
+	return func() {
+		for _, snapshot := range snapshots {
+			snapshot.value.SetLen(snapshot.length)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package makroud_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"net"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+// flakyConnector wraps a connector to reset the connection on the next queries matching a pattern.
+type flakyConnector struct {
+	driver.Connector
+	pattern  string
+	failures int32
+}
+
+func (c *flakyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &flakyConn{Conn: conn, connector: c}, nil
+}
+
+func (c *flakyConnector) fail(query string) error {
+	if !strings.Contains(query, c.pattern) || atomic.AddInt32(&c.failures, -1) < 0 {
+		return nil
+	}
+	return &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+}
+
+type flakyConn struct {
+	driver.Conn
+	connector *flakyConnector
+}
+
+func (c *flakyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	err := c.connector.fail(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *flakyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	err := c.connector.fail(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+func (c *flakyConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	is := require.New(t)
+
+	between := func(delay time.Duration, expected time.Duration) {
+		is.True(delay >= expected/2, "%s should be greater than %s", delay, expected/2)
+		is.True(delay <= expected, "%s should be lower than %s", delay, expected)
+	}
+
+	policy := makroud.RetryPolicy{
+		MaxAttempts: 10,
+		MinBackoff:  10 * time.Millisecond,
+		MaxBackoff:  50 * time.Millisecond,
+	}
+
+	between(policy.Backoff(1), 10*time.Millisecond)
+	between(policy.Backoff(2), 20*time.Millisecond)
+	between(policy.Backoff(3), 40*time.Millisecond)
+	between(policy.Backoff(4), 50*time.Millisecond)
+	between(policy.Backoff(9), 50*time.Millisecond)
+
+	policy.MaxBackoff = 0
+
+	between(policy.Backoff(1), 10*time.Millisecond)
+	between(policy.Backoff(2), 20*time.Millisecond)
+	between(policy.Backoff(4), 80*time.Millisecond)
+	between(policy.Backoff(9), 2560*time.Millisecond)
+
+	policy.MinBackoff = 0
+	is.Equal(time.Duration(0), policy.Backoff(3))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	connector := &flakyConnector{pattern: "ztp_owl"}
+	factory := func(dsn string) (driver.Connector, error) {
+		base, err := pq.NewConnector(dsn)
+		connector.Connector = base
+		return connector, err
+	}
+
+	policy := makroud.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	}
+
+	Setup(t, makroud.WithConnector(factory), makroud.WithRetryPolicy(policy))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		GenerateZootopiaFixtures(ctx, driver, is)
+
+		// Reads are retried until the policy is exhausted.
+		atomic.StoreInt32(&connector.failures, 2)
+		owls := []Owl{}
+		err := makroud.Select(ctx, driver, &owls)
+		is.NoError(err)
+		is.NotEmpty(owls)
+		is.Equal(int32(-1), atomic.LoadInt32(&connector.failures))
+
+		expected := len(owls)
+		atomic.StoreInt32(&connector.failures, 2)
+		count, err := makroud.Count(ctx, driver, loukoum.Select("COUNT(*)").From("ztp_owl"))
+		is.NoError(err)
+		is.Equal(int64(expected), count)
+
+		atomic.StoreInt32(&connector.failures, 3)
+		owls = []Owl{}
+		err = makroud.Select(ctx, driver, &owls)
+		is.Error(err)
+
+		// Mutations are only retried if explicitly marked as safe.
+		update := loukoum.Update("ztp_owl").Set(loukoum.Map{"favorite_food": "Mice"})
+
+		atomic.StoreInt32(&connector.failures, 1)
+		err = makroud.Exec(ctx, driver, update)
+		is.Error(err)
+
+		atomic.StoreInt32(&connector.failures, 1)
+		err = makroud.Exec(makroud.MarkRetrySafe(ctx), driver, update)
+		is.NoError(err)
+
+		// Operations within a transaction are never retried.
+		err = makroud.Transaction(ctx, driver, nil, func(tx makroud.Driver) error {
+			atomic.StoreInt32(&connector.failures, 1)
+			owls := []Owl{}
+			return makroud.Select(ctx, tx, &owls)
+		})
+		is.Error(err)
+	})
+}
@@ -35,7 +35,9 @@ func save(ctx context.Context, driver Driver, model Model) error {
 	pk := schema.PrimaryKey()
 	id, hasPK := pk.ValueOpt(model)
 
-	err = generateSaveQuery(schema, model, hasPK, &returning, values)
+	dialect := getDialect(driver)
+
+	err = generateSaveQuery(dialect, schema, model, hasPK, &returning, values)
 	if err != nil {
 		return err
 	}
@@ -45,6 +47,21 @@ func save(ctx context.Context, driver Driver, model Model) error {
 		return err
 	}
 
+	if len(returning) == 0 {
+		err = Exec(ctx, driver, builder)
+		if err != nil {
+			return withSchemaField(err, schema)
+		}
+		return nil
+	}
+
+	if !dialect.SupportsReturning() {
+		if !hasPK {
+			id = values[pk.ColumnName()]
+		}
+		return saveWithoutReturning(ctx, driver, schema, model, builder, returning, id)
+	}
+
 	err = Exec(ctx, driver, builder, model)
 
 	// Ignore no rows error if returning is empty.
@@ -52,10 +69,16 @@ func save(ctx context.Context, driver Driver, model Model) error {
 		return nil
 	}
 
-	return err
+	if err != nil {
+		return withSchemaField(err, schema)
+	}
+
+	return nil
 }
 
-func generateSaveQuery(schema *Schema, model Model, hasPK bool, returning *[]string, values loukoum.Map) error {
+func generateSaveQuery(dialect Dialect, schema *Schema, model Model,
+	hasPK bool, returning *[]string, values loukoum.Map) error {
+
 	instance := reflectx.GetIndirectValue(model)
 	for _, column := range schema.fields {
 		if column.IsPrimaryKey() {
@@ -74,7 +97,7 @@ func generateSaveQuery(schema *Schema, model Model, hasPK bool, returning *[]str
 
 		} else if column.IsUpdatedKey() && hasPK {
 
-			values[name] = loukoum.Raw("NOW()")
+			values[name] = loukoum.Raw(dialect.CurrentTimestamp())
 			(*returning) = append((*returning), name)
 
 		} else {
@@ -115,16 +138,48 @@ func getSaveBuilder(driver Driver, model Model, pk PrimaryKey,
 		}
 
 		builder := loukoum.Insert(model.TableName()).
-			Set(values).
-			Returning((*returning))
+			Set(values)
+
+		if !getDialect(driver).SupportsReturning() {
+			if pk.Default() == PrimaryKeyDBDefault {
+				return nil, errors.Errorf("%s dialect cannot retrieve a primary key generated by database",
+					getDialect(driver).Name())
+			}
+			return builder, nil
+		}
 
-		return builder, nil
+		return builder.Returning((*returning)), nil
 	}
 
 	builder := loukoum.Update(model.TableName()).
 		Set(values).
-		Where(loukoum.Condition(pk.ColumnName()).Equal(id)).
-		Returning((*returning))
+		Where(loukoum.Condition(pk.ColumnName()).Equal(id))
+
+	if !getDialect(driver).SupportsReturning() {
+		return builder, nil
+	}
 
-	return builder, nil
+	return builder.Returning((*returning)), nil
+}
+
+// saveWithoutReturning executes given save query for a dialect that doesn't support a RETURNING clause.
+// Columns that would have been returned are then retrieved with another query, using the primary key.
+func saveWithoutReturning(ctx context.Context, driver Driver, schema *Schema, model Model,
+	builder builder.Builder, returning []string, id interface{}) error {
+
+	err := Exec(ctx, driver, builder)
+	if err != nil {
+		return withSchemaField(err, schema)
+	}
+
+	query := loukoum.Select(returning).
+		From(schema.TableName()).
+		Where(loukoum.Condition(schema.PrimaryKey().ColumnName()).Equal(id))
+
+	err = Exec(ctx, driver, query, model)
+	if err != nil {
+		return withSchemaField(err, schema)
+	}
+
+	return nil
 }
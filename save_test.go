@@ -2,9 +2,12 @@ package makroud_test
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"github.com/ulule/loukoum/v3"
 
@@ -124,3 +127,32 @@ func TestSave_Meow(t *testing.T) {
 
 	})
 }
+
+func TestSave_DriverError(t *testing.T) {
+	Setup(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		owl := &Owl{
+			Name:         "Blake",
+			FeatherColor: "brown",
+			FavoriteFood: "Raspberry",
+			GroupID:      sql.NullInt64{Int64: 404, Valid: true},
+		}
+
+		err := makroud.Save(ctx, driver, owl)
+		is.Error(err)
+		is.True(makroud.IsForeignKeyViolation(err))
+		is.False(makroud.IsUniqueViolation(err))
+
+		driverErr, ok := makroud.AsDriverError(err)
+		is.True(ok)
+		is.Equal("ztp_owl", driverErr.Table)
+		is.Equal("group_id", driverErr.Column)
+		is.Equal("GroupID", driverErr.Field)
+		is.NotEmpty(driverErr.Constraint)
+
+		_, ok = errors.Cause(err).(*pq.Error)
+		is.True(ok)
+	})
+}
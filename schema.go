@@ -192,23 +192,15 @@ func (schema Schema) getValues(value reflect.Value, columns []string, model Mode
 		}
 
 		// sorting associations columns in case of JOIN
-		found := false
-		for key, association := range schema.associations {
-			trimed := strings.TrimPrefix(column, fmt.Sprint(association.Remote().TableName(), "."))
-			if trimed != column {
-				_, ok := associationsColumns[key]
-				if !ok {
-					associationsColumns[key] = map[string]int{}
-				}
-
-				// we keep the index in values for the scan
-				associationsColumns[key][trimed] = i
-				found = true
-				break
+		key, trimed, found := schema.getAssociationColumn(column)
+		if found {
+			_, ok := associationsColumns[key]
+			if !ok {
+				associationsColumns[key] = map[string]int{}
 			}
-		}
 
-		if found {
+			// we keep the index in values for the scan
+			associationsColumns[key][trimed] = i
 			continue
 		}
 
@@ -249,6 +241,26 @@ func (schema Schema) getValues(value reflect.Value, columns []string, model Mode
 	return values, nil
 }
 
+// getAssociationColumn returns the association name and the remote column for given column of a JOIN.
+// The column is prefixed either by the association name, or by its remote table name.
+func (schema Schema) getAssociationColumn(column string) (string, string, bool) {
+	for key := range schema.associations {
+		trimed := strings.TrimPrefix(column, fmt.Sprint(key, "."))
+		if trimed != column {
+			return key, trimed, true
+		}
+	}
+
+	for key, association := range schema.associations {
+		trimed := strings.TrimPrefix(column, fmt.Sprint(association.Remote().TableName(), "."))
+		if trimed != column {
+			return key, trimed, true
+		}
+	}
+
+	return "", "", false
+}
+
 // ScanRow executes a scan from given row into model.
 func (schema Schema) ScanRow(row Row, model Model) error {
 	columns, err := row.Columns()
@@ -13,7 +13,8 @@ import (
 )
 
 // Select retrieves the given instance using given arguments as criteria.
-// This method accepts loukoum's stmt.Order, stmt.Offet, stmt.Limit and stmt.Expression as arguments.
+// This method accepts loukoum's stmt.Order, stmt.Offet, stmt.Limit and stmt.Expression as arguments, and
+// JoinPreloadHandler to eagerly load associations.
 // For unsupported statement, they will be ignored.
 func Select(ctx context.Context, driver Driver, dest interface{}, args ...interface{}) error {
 	if !reflectx.IsPointer(dest) {
@@ -36,17 +37,26 @@ func selectRow(ctx context.Context, driver Driver, dest interface{}, args []inte
 		return errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", dest)
 	}
 
-	columns := schema.ColumnPaths()
+	handlers := getJoinPreloadHandlers(args)
 
-	query, parsed := parseSelectArgs(loukoum.Select(columns.List()).From(model.TableName()), args)
+	query, err := newSelectBuilder(schema, handlers)
+	if err != nil {
+		return err
+	}
+
+	query, parsed := parseSelectArgs(query, args)
 	if !parsed.hasLimit {
 		query = query.Limit(1)
 	}
 	if !parsed.hasOrder {
-		query = query.OrderBy(loukoum.Order(schema.PrimaryKeyName()))
+		query = query.OrderBy(loukoum.Order(schema.PrimaryKeyPath()))
 	}
 	if schema.HasDeletedKey() {
-		query = query.Where(loukoum.Condition(schema.DeletedKeyName()).IsNull(true))
+		query = query.Where(loukoum.Condition(schema.DeletedKeyPath()).IsNull(true))
+	}
+
+	if len(handlers) > 0 {
+		return execJoinPreload(ctx, driver, schema, query, dest, handlers)
 	}
 
 	return Exec(ctx, driver, query, dest)
@@ -63,17 +73,36 @@ func selectRows(ctx context.Context, driver Driver, dest interface{}, args []int
 		return errors.Wrapf(err, "makroud: cannot fetch schema informations on %T", dest)
 	}
 
-	columns := schema.ColumnPaths()
+	handlers := getJoinPreloadHandlers(args)
 
-	query, parsed := parseSelectArgs(loukoum.Select(columns.List()).From(model.TableName()), args)
+	query, err := getSelectRowsBuilder(schema, handlers, args)
+	if err != nil {
+		return err
+	}
+
+	if len(handlers) > 0 {
+		return execJoinPreload(ctx, driver, schema, query, dest, handlers)
+	}
+
+	return Exec(ctx, driver, query, dest)
+}
+
+// getSelectRowsBuilder returns the query used to retrieve every instance of given schema matching given arguments.
+func getSelectRowsBuilder(schema *Schema, handlers []JoinPreloadHandler, args []interface{}) (builder.Select, error) {
+	query, err := newSelectBuilder(schema, handlers)
+	if err != nil {
+		return query, err
+	}
+
+	query, parsed := parseSelectArgs(query, args)
 	if !parsed.hasOrder {
-		query = query.OrderBy(loukoum.Order(schema.PrimaryKeyName()))
+		query = query.OrderBy(loukoum.Order(schema.PrimaryKeyPath()))
 	}
 	if schema.HasDeletedKey() {
-		query = query.Where(loukoum.Condition(schema.DeletedKeyName()).IsNull(true))
+		query = query.Where(loukoum.Condition(schema.DeletedKeyPath()).IsNull(true))
 	}
 
-	return Exec(ctx, driver, query, dest)
+	return query, nil
 }
 
 type parsedSelectArgs struct {
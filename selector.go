@@ -22,6 +22,7 @@ type SelectorConfigurations map[string]*ClientOptions
 // Selector contains a pool of drivers indexed by their name.
 type Selector struct {
 	mutex          sync.RWMutex
+	closing        bool
 	cache          *DriverCache
 	configurations map[string]*ClientOptions
 	connections    map[string]Driver
@@ -84,6 +85,10 @@ func (selector *Selector) Using(alias string) (Driver, error) {
 		return connection, nil
 	}
 
+	if selector.closing {
+		return nil, errors.Wrapf(ErrShutdown, "connection alias '%s' cannot be opened", alias)
+	}
+
 	for name, configuration := range selector.configurations {
 		if alias == strings.ToLower(name) {
 
@@ -0,0 +1,146 @@
+package makroud
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// lifecycle tracks the in-flight operations of a client, and its transactions, for a graceful shutdown.
+type lifecycle struct {
+	mutex    sync.Mutex
+	closing  bool
+	inflight int
+	idle     context.Context
+	notify   context.CancelFunc
+}
+
+// acquire registers a new in-flight operation.
+// It returns a function to call once the operation is done, or ErrShutdown if the client is shutting down.
+func (life *lifecycle) acquire() (func(), error) {
+	life.mutex.Lock()
+	defer life.mutex.Unlock()
+
+	if life.closing {
+		return nil, errors.WithStack(ErrShutdown)
+	}
+
+	life.inflight++
+
+	once := sync.Once{}
+	return func() {
+		once.Do(life.release)
+	}, nil
+}
+
+// release unregisters an in-flight operation.
+func (life *lifecycle) release() {
+	life.mutex.Lock()
+	defer life.mutex.Unlock()
+
+	life.inflight--
+	if life.closing && life.inflight == 0 && life.notify != nil {
+		life.notify()
+		life.idle, life.notify = nil, nil
+	}
+}
+
+// shutdown rejects every new operation and waits for in-flight ones until given context is done.
+func (life *lifecycle) shutdown(ctx context.Context) error {
+	life.mutex.Lock()
+	life.closing = true
+	if life.inflight == 0 {
+		life.mutex.Unlock()
+		return nil
+	}
+	if life.idle == nil {
+		// A canceled context is used as a broadcast once every in-flight operation is done.
+		life.idle, life.notify = context.WithCancel(context.Background())
+	}
+	idle := life.idle
+	life.mutex.Unlock()
+
+	select {
+	case <-idle.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// noop is used when an operation doesn't need to be tracked.
+func noop() {}
+
+// enter registers a new in-flight operation on client.
+// Operations within a transaction are always accepted, since the transaction is tracked until its commit or
+// rollback.
+func (c *Client) enter() (func(), error) {
+	if c.life == nil || c.node.Tx() != nil {
+		return noop, nil
+	}
+	return c.life.acquire()
+}
+
+// Shutdown gracefully closes the client: new operations are rejected with ErrShutdown, then it waits for
+// in-flight queries and transactions to finish, until given context is done, and finally closes the underlying
+// connection.
+func (c *Client) Shutdown(ctx context.Context) error {
+	var err error
+	if c.life != nil {
+		err = c.life.shutdown(ctx)
+	}
+
+	thr := c.Close()
+	if err != nil {
+		return errors.Wrap(err, "makroud: cannot wait for in-flight operations")
+	}
+
+	return thr
+}
+
+// Shutdown gracefully closes every connection of the selector, see Client.Shutdown for more information.
+// Once called, the selector doesn't open new connections.
+func (selector *Selector) Shutdown(ctx context.Context) error {
+	selector.mutex.Lock()
+	selector.closing = true
+	connections := selector.connections
+	selector.connections = map[string]Driver{}
+	selector.mutex.Unlock()
+
+	type shutdowner interface {
+		Shutdown(ctx context.Context) error
+	}
+
+	wg := sync.WaitGroup{}
+	mutex := sync.Mutex{}
+	failures := []error{}
+
+	for alias, connection := range connections {
+		wg.Add(1)
+		go func(alias string, connection Driver) {
+			defer wg.Done()
+
+			var err error
+			handler, ok := connection.(shutdowner)
+			if ok {
+				err = handler.Shutdown(ctx)
+			} else {
+				err = connection.Close()
+			}
+			if err != nil {
+				mutex.Lock()
+				failures = append(failures, errors.Wrapf(err, "cannot shutdown drivers connection for %s", alias))
+				mutex.Unlock()
+			}
+		}(alias, connection)
+	}
+
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return failures[0]
+	}
+
+	return nil
+}
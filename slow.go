@@ -0,0 +1,40 @@
+package makroud
+
+import (
+	"context"
+	"time"
+)
+
+// reportSlowQuery will emmit given query on driver's attached Observer if it exceeded the slow query threshold.
+// If enabled, the execution plan of the query is attached to the report.
+func reportSlowQuery(ctx context.Context, driver Driver, query Query, duration time.Duration) {
+	if driver == nil || !driver.HasObserver() {
+		return
+	}
+
+	threshold := driver.SlowQueryThreshold()
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	observer, ok := driver.Observer().(SlowQueryObserver)
+	if !ok {
+		return
+	}
+
+	report := SlowQuery{
+		Query:     query,
+		Duration:  duration,
+		Threshold: threshold,
+	}
+
+	if driver.HasSlowQueryExplain() {
+		statement := query.Query
+		if statement == "" {
+			statement = query.Raw
+		}
+		report.Plan, report.Err = driver.Explain(ctx, statement, query.Args...)
+	}
+
+	observer.OnSlowQuery(ctx, report)
+}
@@ -0,0 +1,118 @@
+package makroud_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+type slowObserver struct {
+	queries chan makroud.SlowQuery
+}
+
+func (e *slowObserver) OnClose(err error, flags map[string]string) {}
+
+func (e *slowObserver) OnRollback(err error, flags map[string]string) {}
+
+func (e *slowObserver) OnSlowQuery(ctx context.Context, query makroud.SlowQuery) {
+	e.queries <- query
+}
+
+var ErrSlowQueryTimeout = fmt.Errorf("slow query timeout")
+
+func (e *slowObserver) read() (makroud.SlowQuery, error) {
+	select {
+	case query := <-e.queries:
+		return query, nil
+	case <-time.After(500 * time.Millisecond):
+		return makroud.SlowQuery{}, ErrSlowQueryTimeout
+	}
+}
+
+func TestSlowQuery(t *testing.T) {
+	observer := &slowObserver{
+		queries: make(chan makroud.SlowQuery, 10),
+	}
+	Setup(t, makroud.WithObserver(observer), makroud.SlowQueryThreshold(time.Nanosecond))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		owl := &Owl{
+			Name:         "Hedwig",
+			FeatherColor: "white",
+			FavoriteFood: "Bacon",
+		}
+
+		err := makroud.Save(ctx, driver, owl)
+		is.NoError(err)
+
+		query, err := observer.read()
+		is.NoError(err)
+		is.Contains(query.Query.Query, "INSERT INTO ztp_owl")
+		is.Equal(time.Nanosecond, query.Threshold)
+		is.True(query.Duration >= query.Threshold)
+		is.Empty(query.Plan)
+		is.NoError(query.Err)
+	})
+}
+
+func TestSlowQuery_Explain(t *testing.T) {
+	observer := &slowObserver{
+		queries: make(chan makroud.SlowQuery, 10),
+	}
+	options := []makroud.Option{
+		makroud.WithObserver(observer),
+		makroud.SlowQueryThreshold(time.Nanosecond),
+		makroud.EnableSlowQueryExplain(),
+	}
+	Setup(t, options...)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		owls := []Owl{}
+		err := makroud.Select(ctx, driver, &owls, loukoum.Condition("name").Equal("Hedwig"))
+		is.NoError(err)
+
+		query, err := observer.read()
+		is.NoError(err)
+		is.Contains(query.Query.Query, "FROM ztp_owl")
+		is.Equal([]interface{}{"Hedwig"}, query.Query.Args)
+		is.NoError(query.Err)
+		is.NotEmpty(query.Plan)
+
+		plan := []map[string]interface{}{}
+		err = json.Unmarshal(query.Plan, &plan)
+		is.NoError(err)
+		is.Len(plan, 1)
+		is.Contains(plan[0], "Plan")
+	})
+}
+
+func TestSlowQuery_Disabled(t *testing.T) {
+	observer := &slowObserver{
+		queries: make(chan makroud.SlowQuery, 10),
+	}
+	Setup(t, makroud.WithObserver(observer), makroud.SlowQueryThreshold(time.Hour))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		owl := &Owl{
+			Name:         "Errol",
+			FeatherColor: "grey",
+			FavoriteFood: "Mice",
+		}
+
+		err := makroud.Save(ctx, driver, owl)
+		is.NoError(err)
+
+		_, err = observer.read()
+		is.Equal(ErrSlowQueryTimeout, err)
+	})
+}
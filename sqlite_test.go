@@ -0,0 +1,216 @@
+//go:build sqlite
+// +build sqlite
+
+package makroud_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/makroud"
+)
+
+// SQLite integration tests are excluded from the default test suite since they require a SQLite driver.
+// They can be executed with: go test -tags sqlite -run SQLite ./...
+
+func SetupSQLite(t *testing.T, options ...makroud.Option) SetupCallback {
+	is := require.New(t)
+	ctx := context.Background()
+
+	node, err := makroud.Connect("sqlite3", filepath.Join(t.TempDir(), "makroud_test.db"))
+	is.NoError(err)
+	is.NotNil(node)
+
+	options = append([]makroud.Option{
+		makroud.WithNode(node),
+		makroud.WithDialect(makroud.SQLiteDialect{}),
+		makroud.Cache(true),
+	}, options...)
+
+	clientOpts := makroud.NewClientOptions()
+	for _, option := range options {
+		is.NoError(option(clientOpts))
+	}
+
+	db, err := makroud.NewWithOptions(clientOpts)
+	is.NoError(err)
+	is.NotNil(db)
+
+	return func(handler SetupHandler) {
+		db.MustExec(ctx, `
+			CREATE TABLE ztp_cat (
+				id                VARCHAR(26) PRIMARY KEY NOT NULL,
+				name              VARCHAR(255) NOT NULL,
+				created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				deleted_at        TIMESTAMP
+			);
+			CREATE TABLE ztp_meow (
+				hash              VARCHAR(26) PRIMARY KEY NOT NULL,
+				body              VARCHAR(2048) NOT NULL,
+				cat_id            VARCHAR(26) NOT NULL REFERENCES ztp_cat(id),
+				created           TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated           TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				deleted           TIMESTAMP
+			);
+			CREATE TABLE ztp_human (
+				id                VARCHAR(26) PRIMARY KEY NOT NULL,
+				name              VARCHAR(255) NOT NULL,
+				cat_id            VARCHAR(26) REFERENCES ztp_cat(id),
+				created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				deleted_at        TIMESTAMP
+			);
+		`)
+		handler(db)
+		is.NoError(db.Close())
+	}
+}
+
+func TestSQLite_Save(t *testing.T) {
+	SetupSQLite(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		cat := &Cat{Name: "Hemingway"}
+		err := makroud.Save(ctx, driver, cat)
+		is.NoError(err)
+		is.NotEmpty(cat.ID)
+		is.False(cat.CreatedAt.IsZero())
+		is.False(cat.UpdatedAt.IsZero())
+
+		meow := &Meow{Body: "meow", CatID: cat.ID}
+		err = makroud.Save(ctx, driver, meow)
+		is.NoError(err)
+		is.NotEmpty(meow.Hash)
+
+		cat.Name = "Papa"
+		err = makroud.Save(ctx, driver, cat)
+		is.NoError(err)
+
+		last := &Cat{}
+		err = makroud.Select(ctx, driver, last, loukoum.Condition("id").Equal(cat.ID))
+		is.NoError(err)
+		is.Equal("Papa", last.Name)
+		is.Equal(cat.CreatedAt.Unix(), last.CreatedAt.Unix())
+
+		err = makroud.Transaction(ctx, driver, nil, func(tx makroud.Driver) error {
+			cat.Name = "Hemingway"
+			return makroud.Save(ctx, tx, cat)
+		})
+		is.NoError(err)
+
+		last = &Cat{}
+		err = makroud.Select(ctx, driver, last, loukoum.Condition("id").Equal(cat.ID))
+		is.NoError(err)
+		is.Equal("Hemingway", last.Name)
+	})
+}
+
+func TestSQLite_Select(t *testing.T) {
+	SetupSQLite(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		names := []string{"Hemingway", "Papa", "Shadow"}
+		for i := range names {
+			cat := &Cat{Name: names[i]}
+			err := makroud.Save(ctx, driver, cat)
+			is.NoError(err)
+
+			for j := 0; j <= i; j++ {
+				meow := &Meow{Body: fmt.Sprint("meow #", j), CatID: cat.ID}
+				err = makroud.Save(ctx, driver, meow)
+				is.NoError(err)
+			}
+		}
+
+		cats := []*Cat{}
+		err := makroud.Select(ctx, driver, &cats,
+			loukoum.Condition("name").In("Papa", "Shadow"),
+			loukoum.Order("name"),
+		)
+		is.NoError(err)
+		is.Len(cats, 2)
+		is.Equal("Papa", cats[0].Name)
+		is.Equal("Shadow", cats[1].Name)
+
+		err = makroud.Preload(ctx, driver, &cats, makroud.WithPreloadField("Meows"))
+		is.NoError(err)
+		is.Len(cats[0].Meows, 2)
+		is.Len(cats[1].Meows, 3)
+
+		count, err := makroud.Count(ctx, driver, loukoum.Select("COUNT(*)").From("ztp_meow").
+			Where(loukoum.Condition("cat_id").Equal(cats[1].ID)))
+		is.NoError(err)
+		is.Equal(int64(3), count)
+	})
+}
+
+func TestSQLite_Delete(t *testing.T) {
+	SetupSQLite(t)(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		cat := &Cat{Name: "Hemingway"}
+		err := makroud.Save(ctx, driver, cat)
+		is.NoError(err)
+
+		err = makroud.Archive(ctx, driver, cat)
+		is.NoError(err)
+		is.True(cat.DeletedAt.Valid)
+
+		last := &Cat{}
+		err = makroud.Select(ctx, driver, last, loukoum.Condition("id").Equal(cat.ID))
+		is.Error(err)
+		is.True(makroud.IsErrNoRows(err))
+
+		count, err := makroud.Count(ctx, driver, loukoum.Select("COUNT(*)").From("ztp_cat").
+			Where(loukoum.Condition("id").Equal(cat.ID)).And(loukoum.Condition("deleted_at").IsNull(false)))
+		is.NoError(err)
+		is.Equal(int64(1), count)
+
+		err = makroud.Delete(ctx, driver, cat)
+		is.NoError(err)
+
+		count, err = makroud.Count(ctx, driver, loukoum.Select("COUNT(*)").From("ztp_cat").
+			Where(loukoum.Condition("id").Equal(cat.ID)))
+		is.NoError(err)
+		is.Equal(int64(0), count)
+	})
+}
+
+func TestSQLite_NPlusOne(t *testing.T) {
+	observer := &nPlusOneObserver{}
+	SetupSQLite(t, makroud.WithObserver(observer), makroud.DetectNPlusOne(2))(func(driver makroud.Driver) {
+		is := require.New(t)
+		ctx, _ := makroud.NewQueryScope(context.Background())
+
+		cats := []*Cat{}
+		for _, name := range []string{"Hemingway", "Papa", "Shadow"} {
+			cat := &Cat{Name: name}
+			err := makroud.Save(ctx, driver, cat)
+			is.NoError(err)
+			cats = append(cats, cat)
+		}
+
+		for i := range cats {
+			meows := []*Meow{}
+			err := makroud.Select(ctx, driver, &meows, loukoum.Condition("cat_id").Equal(cats[i].ID))
+			is.NoError(err)
+		}
+
+		is.Len(observer.detections, 1)
+		detection := observer.detections[0]
+		is.Equal("ztp_meow", detection.Table)
+		is.Contains(detection.Query, "cat_id = ?)")
+		is.NotContains(detection.Query, "?1")
+		is.Contains(detection.Suggestions, `Cat: WithPreloadField("Meows")`)
+	})
+}
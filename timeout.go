@@ -0,0 +1,34 @@
+package makroud
+
+import (
+	"context"
+	"time"
+)
+
+type timeoutKey struct{}
+
+// WithoutTimeout returns a new context on which the default query and transaction timeouts of the driver are
+// not applied, for example, to run a long report.
+func WithoutTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, true)
+}
+
+// withTimeout applies given default timeout on context, unless it has already a deadline or if timeouts are
+// disabled on this context.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, noop
+	}
+
+	_, ok := ctx.Deadline()
+	if ok {
+		return ctx, noop
+	}
+
+	skip, _ := ctx.Value(timeoutKey{}).(bool)
+	if skip {
+		return ctx, noop
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,21 @@
+package makroud
+
+import (
+	"context"
+	"time"
+)
+
+// isTraced returns if queries executed with given driver and context should be traced.
+func isTraced(ctx context.Context, driver Driver) bool {
+	if driver.HasLogger() || driver.SlowQueryThreshold() > 0 {
+		return true
+	}
+	return driver.NPlusOneThreshold() > 0 && GetQueryScope(ctx) != nil
+}
+
+// trace will emmit given query on every components attached to the driver that are interested by it.
+func trace(ctx context.Context, driver Driver, query Query, duration time.Duration) {
+	Log(ctx, driver, query, duration)
+	reportSlowQuery(ctx, driver, query, duration)
+	recordQueryShape(ctx, driver, query)
+}
@@ -28,6 +28,12 @@ func Transaction(ctx context.Context, driver Driver, opts *TxOptions,
 		return errors.Wrap(ErrInvalidDriver, "makroud: cannot create a transaction")
 	}
 
+	if !driver.InTransaction() {
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, driver.TransactionTimeout())
+		defer cancel()
+	}
+
 	tx, err := driver.Begin(ctx, opts)
 	if err != nil {
 		return err
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
@@ -142,6 +143,79 @@ func TestTransaction_Nested(t *testing.T) {
 	})
 }
 
+func TestTransaction_State(t *testing.T) {
+	Setup(t, makroud.EnableSavepoint())(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		is.False(driver.InTransaction())
+		is.False(driver.IsNested())
+		is.Equal(0, driver.NestingDepth())
+		is.Empty(driver.SavepointName())
+
+		err := makroud.Transaction(ctx, driver, nil, func(tx1 makroud.Driver) error {
+			is.True(tx1.InTransaction())
+			is.False(tx1.IsNested())
+			is.Equal(1, tx1.NestingDepth())
+			is.Empty(tx1.SavepointName())
+			is.True(tx1.Stats().InUse > 0)
+
+			return makroud.Transaction(ctx, tx1, nil, func(tx2 makroud.Driver) error {
+				is.True(tx2.InTransaction())
+				is.True(tx2.IsNested())
+				is.Equal(2, tx2.NestingDepth())
+				is.NotEmpty(tx2.SavepointName())
+				return nil
+			})
+		})
+		is.NoError(err)
+
+		stats := driver.Stats()
+		is.Equal(0, stats.InUse)
+		is.True(stats.OpenConnections > 0)
+	})
+}
+
+func TestTransaction_Timeout(t *testing.T) {
+	Setup(t, makroud.DefaultTransactionTimeout(100*time.Millisecond))(func(driver makroud.Driver) {
+		ctx := context.Background()
+		is := require.New(t)
+
+		cat := &Cat{Name: "Gemmz"}
+
+		err := makroud.Save(ctx, driver, cat)
+		is.NoError(err)
+
+		err = makroud.Transaction(ctx, driver, nil, func(tx makroud.Driver) error {
+			cat.Name = "Gemma"
+			err := makroud.Save(ctx, tx, cat)
+			is.NoError(err)
+			time.Sleep(300 * time.Millisecond)
+			return nil
+		})
+		is.Error(err)
+
+		name := ""
+		query := loukoum.Select("name").From("ztp_cat").Where(loukoum.Condition("id").Equal(cat.ID))
+		err = makroud.Exec(ctx, driver, query, &name)
+		is.NoError(err)
+		is.Equal("Gemmz", name)
+
+		err = makroud.Transaction(makroud.WithoutTimeout(ctx), driver, nil, func(tx makroud.Driver) error {
+			cat.Name = "Gemma"
+			err := makroud.Save(ctx, tx, cat)
+			is.NoError(err)
+			time.Sleep(300 * time.Millisecond)
+			return nil
+		})
+		is.NoError(err)
+
+		err = makroud.Exec(ctx, driver, query, &name)
+		is.NoError(err)
+		is.Equal("Gemma", name)
+	})
+}
+
 func TestTransaction_IsolationLevel(t *testing.T) {
 	Setup(t, makroud.EnableSavepoint())(func(driver makroud.Driver) {
 		ctx := context.Background()